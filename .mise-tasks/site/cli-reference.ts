@@ -9,9 +9,17 @@ async function generateCLIReferenceDocs() {
     ["init"],
     ["create"],
     ["bump"],
+    ["infer"],
     ["commit"],
+    ["check"],
+    ["status"],
+    ["tag"],
     ["current"],
     ["cat"],
+    ["notes"],
+    ["pre"],
+    ["pre", "enter"],
+    ["pre", "exit"],
     ["builtins"],
     ["builtins", "current:default"],
     ["builtins", "current:file"],
@@ -19,6 +27,7 @@ async function generateCLIReferenceDocs() {
     ["builtins", "current:toml"],
     ["builtins", "current:json"],
     ["builtins", "current:yaml"],
+    ["builtins", "current:git-tag"],
     ["builtins", "next:default"],
     ["builtins", "next:file"],
     ["builtins", "next:npm"],
@@ -27,6 +36,7 @@ async function generateCLIReferenceDocs() {
     ["builtins", "next:yaml"],
     ["builtins", "amendlog:default"],
     ["builtins", "cat:default"],
+    ["builtins", "lint:changelog"],
   ];
 
   await rm("./site/src/content/docs/cli", { recursive: true, force: true });
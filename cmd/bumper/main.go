@@ -16,11 +16,18 @@ import (
 	"github.com/disintegrator/bumper/internal/commands/builtins"
 	"github.com/disintegrator/bumper/internal/commands/bump"
 	"github.com/disintegrator/bumper/internal/commands/cat"
+	"github.com/disintegrator/bumper/internal/commands/check"
 	"github.com/disintegrator/bumper/internal/commands/commit"
 	"github.com/disintegrator/bumper/internal/commands/create"
 	"github.com/disintegrator/bumper/internal/commands/current"
+	"github.com/disintegrator/bumper/internal/commands/infer"
 	"github.com/disintegrator/bumper/internal/commands/initialize"
 	"github.com/disintegrator/bumper/internal/commands/next"
+	"github.com/disintegrator/bumper/internal/commands/notes"
+	"github.com/disintegrator/bumper/internal/commands/pre"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/commands/status"
+	"github.com/disintegrator/bumper/internal/commands/tag"
 	"github.com/disintegrator/bumper/internal/o11y"
 )
 
@@ -30,14 +37,23 @@ func newRootCommand(logger *slog.Logger) *cli.Command {
 		Usage:   "A tool for managing versioning and changelogs",
 		Version: buildinfo.Version,
 		Authors: []any{"Georges Haidar (github.com/disintegrator)"},
+		Flags: []cli.Flag{
+			shared.NewOutputFlag(),
+		},
 		Commands: []*cli.Command{
 			initialize.NewCommand(logger),
 			create.NewCommand(logger),
 			bump.NewCommand(logger),
+			infer.NewCommand(logger),
 			commit.NewCommand(logger),
+			check.NewCommand(logger),
+			status.NewCommand(logger),
+			tag.NewCommand(logger),
 			current.NewCommand(logger),
 			next.NewCommand(logger),
 			cat.NewCommand(logger),
+			notes.NewCommand(logger),
+			pre.NewCommand(logger),
 			builtins.NewCommand(logger),
 		},
 	}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/disintegrator/bumper/internal/o11y"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+const goldenConfig = `[[groups]]
+name = "api"
+changelog_cmd = ["true"]
+cat_cmd = ["printf", "## api 1.2.3\n\n- Fixed a bug\n"]
+current_cmd = ["echo", "1.2.3"]
+next_cmd = ["true"]
+`
+
+// TestJSONOutput pins the --output json documents, which pipelines parse.
+// Run with -update to rewrite the golden files after an intended change.
+func TestJSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".bumper"), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".bumper", "config.toml"), []byte(goldenConfig), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".bumper", "bump-fix.md"), []byte("---\napi: patch\n---\n\nFixed a bug\n"), 0o644); err != nil {
+		t.Fatalf("write bump file: %v", err)
+	}
+
+	// Ordered: commit consumes the bump file that next reads.
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{golden: "current.json", args: []string{"current", "--group", "api"}},
+		{golden: "next.json", args: []string{"next", "--group", "api"}},
+		{golden: "cat.json", args: []string{"cat", "--group", "api", "--version", "1.2.3"}},
+		{golden: "commit-dry-run.json", args: []string{"commit", "--dry-run"}},
+		{golden: "commit.json", args: []string{"commit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			logger := slog.New(o11y.NewWarningRecorder(slog.DiscardHandler))
+			root := newRootCommand(logger)
+			var stdout bytes.Buffer
+			root.Writer = &stdout
+
+			args := append([]string{"bumper", "--output", "json"}, tt.args...)
+			args = append(args, "--dir", dir)
+			if err := root.Run(t.Context(), args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := strings.ReplaceAll(stdout.String(), dir, "$DIR")
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatalf("write golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("output mismatch for %s:\ngot:\n%s\nwant:\n%s", tt.golden, got, want)
+			}
+		})
+	}
+}
+
+const builtinsConfig = `[[groups]]
+name = "api"
+changelog_cmd = ["bumper", "builtins", "amendlog:default"]
+cat_cmd = ["bumper", "builtins", "cat:default"]
+current_cmd = ["bumper", "builtins", "current:file", "--path", "VERSION"]
+next_cmd = ["bumper", "b", "next:file", "--path", "VERSION"]
+`
+
+// TestBuiltinsRunInProcess releases a group whose commands are all builtins
+// without bumper on PATH.
+func TestBuiltinsRunInProcess(t *testing.T) {
+	t.Setenv("PATH", "")
+
+	for _, tt := range []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{name: "in-process", config: builtinsConfig},
+		{name: "subprocess", config: "subprocess_builtins = true\n\n" + builtinsConfig, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range map[string]string{
+				".bumper/config.toml": tt.config,
+				".bumper/bump-fix.md": "---\napi: patch\n---\n\nFixed a bug\n",
+				"VERSION":             "1.2.3\n",
+				"CHANGELOG.md":        "# Changelog\n",
+			} {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatalf("create directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatalf("write %s: %v", name, err)
+				}
+			}
+
+			root := newRootCommand(slog.New(slog.DiscardHandler))
+			var stdout bytes.Buffer
+			root.Writer = &stdout
+
+			err := root.Run(t.Context(), []string{"bumper", "commit", "--dir", dir})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected the commit to fail without bumper on PATH")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got, want := stdout.String(), "api\n"; got != want {
+				t.Errorf("stdout = %q, want %q", got, want)
+			}
+			version, err := os.ReadFile(filepath.Join(dir, "VERSION"))
+			if err != nil {
+				t.Fatalf("read VERSION: %v", err)
+			}
+			if got, want := string(version), "1.2.4"; got != want {
+				t.Errorf("VERSION = %q, want %q", got, want)
+			}
+			changelog, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.md"))
+			if err != nil {
+				t.Fatalf("read CHANGELOG.md: %v", err)
+			}
+			if !strings.Contains(string(changelog), "## api 1.2.4") || !strings.Contains(string(changelog), "Fixed a bug") {
+				t.Errorf("CHANGELOG.md = %q, want the 1.2.4 release", changelog)
+			}
+		})
+	}
+}
+
+// writeGroupsWorkspace writes a workspace with the groups api and web, each
+// with its own changelog and VERSION file, and a bump releasing both. Without
+// withVersions, the VERSION files are missing.
+func writeGroupsWorkspace(t *testing.T, withVersions bool) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".bumper/bump-fix.md": "---\napi: patch\nweb: minor\n---\n\nFixed a bug\n",
+	}
+	var config strings.Builder
+	for _, group := range []string{"api", "web"} {
+		fmt.Fprintf(&config, `[[groups]]
+name = %[1]q
+changelog_path = "%[1]s/CHANGELOG.md"
+changelog_cmd = ["bumper", "builtins", "amendlog:default"]
+cat_cmd = ["bumper", "builtins", "cat:default"]
+current_cmd = ["bumper", "builtins", "current:file", "--path", "%[1]s/VERSION"]
+next_cmd = ["bumper", "builtins", "next:file", "--path", "%[1]s/VERSION"]
+
+`, group)
+		if withVersions {
+			files[group+"/VERSION"] = "1.2.3\n"
+		}
+		files[group+"/CHANGELOG.md"] = "# Changelog\n"
+	}
+	files[".bumper/config.toml"] = config.String()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	return dir
+}
+
+// TestBuiltinsRunConcurrently releases two groups whose commands are builtins
+// at once, each resolving its relative paths against the workspace.
+func TestBuiltinsRunConcurrently(t *testing.T) {
+	dir := writeGroupsWorkspace(t, true)
+
+	root := newRootCommand(slog.New(slog.DiscardHandler))
+	root.Writer = io.Discard
+	if err := root.Run(t.Context(), []string{"bumper", "commit", "--dir", dir, "--jobs", "2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for group, want := range map[string]string{"api": "1.2.4", "web": "1.3.0"} {
+		version, err := os.ReadFile(filepath.Join(dir, group, "VERSION"))
+		if err != nil {
+			t.Fatalf("read %s/VERSION: %v", group, err)
+		}
+		if got := string(version); got != want {
+			t.Errorf("%s/VERSION = %q, want %q", group, got, want)
+		}
+		changelog, err := os.ReadFile(filepath.Join(dir, group, "CHANGELOG.md"))
+		if err != nil {
+			t.Fatalf("read %s/CHANGELOG.md: %v", group, err)
+		}
+		if !strings.Contains(string(changelog), "## "+group+" "+want) {
+			t.Errorf("%s/CHANGELOG.md = %q, want the %s release", group, changelog, want)
+		}
+	}
+}
+
+// TestBuiltinsLogToGroupStderr releases two groups at once and checks that
+// the logs of each group's builtins are printed in one piece.
+func TestBuiltinsLogToGroupStderr(t *testing.T) {
+	dir := writeGroupsWorkspace(t, false)
+
+	var stderr bytes.Buffer
+	root := newRootCommand(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	root.Writer = io.Discard
+	root.ErrWriter = &stderr
+	if err := root.Run(t.Context(), []string{"bumper", "commit", "--dir", dir, "--jobs", "2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// current:file warns about the missing file and next:file reports
+	// writing it, so each group logs two lines naming its VERSION file.
+	var owners []string
+	for line := range strings.Lines(stderr.String()) {
+		for _, group := range []string{"api", "web"} {
+			if strings.Contains(line, filepath.Join(dir, group, "VERSION")) {
+				owners = append(owners, group)
+			}
+		}
+	}
+	if len(owners) != 4 {
+		t.Fatalf("stderr = %q, want two lines for each group", stderr.String())
+	}
+	if owners[0] != owners[1] || owners[2] != owners[3] || owners[0] == owners[2] {
+		t.Errorf("log lines by group = %v, want each group's lines together:\n%s", owners, stderr.String())
+	}
+}
+
+// TestAmendlogReadsChangelogInput amends a changelog from the structured
+// input alone, as bumper commit passes it, with the commit of each entry.
+func TestAmendlogReadsChangelogInput(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".bumper"), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".bumper", "config.toml"), []byte(builtinsConfig), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	input := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(input, []byte(`{"group":"api","version":"1.2.4","major":[],"minor":[],"patch":[
+		{"content":"abc1234: Fixed a bug","message":"Fixed a bug","sha":"abc1234def5678901234567890abcdef12345678"},
+		{"content":"cafe123: Fixed a typo","message":"cafe123: Fixed a typo"}
+	]}`), 0o644); err != nil {
+		t.Fatalf("write changelog input: %v", err)
+	}
+	t.Setenv("BUMPER_CHANGELOG_INPUT", input)
+	template := filepath.Join(t.TempDir(), "release.tmpl")
+	if err := os.WriteFile(template, []byte("## {{ .Version }}\n{{ range .Patch }}\n- {{ .Message }}{{ with .Commit }} ({{ . }}){{ end }}{{ end }}"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	root := newRootCommand(slog.New(slog.DiscardHandler))
+	args := []string{"bumper", "builtins", "amendlog:default", "--dir", dir, "--group", "api", "--version", "1.2.4", "--template", template}
+	if err := root.Run(t.Context(), args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changelog, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.md"))
+	if err != nil {
+		t.Fatalf("read CHANGELOG.md: %v", err)
+	}
+	if want := "## 1.2.4\n\n- Fixed a bug (abc1234)\n- cafe123: Fixed a typo\n"; !strings.Contains(string(changelog), want) {
+		t.Errorf("CHANGELOG.md = %q, want it to contain %q", changelog, want)
+	}
+}
+
+// TestNextNpmUpdatesDependencyPins releases an npm package along with a
+// package depending on it, whose pin next:npm updates.
+func TestNextNpmUpdatesDependencyPins(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".bumper/config.toml": `[[groups]]
+name = "sdk"
+changelog_cmd = ["bumper", "builtins", "amendlog:default"]
+cat_cmd = ["bumper", "builtins", "cat:default"]
+current_cmd = ["bumper", "builtins", "current:npm", "--package", "packages/sdk/package.json"]
+next_cmd = ["bumper", "builtins", "next:npm", "--package", "packages/sdk/package.json"]
+
+[[groups]]
+name = "cli"
+depends_on = [{ group = "sdk" }]
+changelog_cmd = ["bumper", "builtins", "amendlog:default"]
+cat_cmd = ["bumper", "builtins", "cat:default"]
+current_cmd = ["bumper", "builtins", "current:npm", "--package", "packages/cli/package.json"]
+next_cmd = ["bumper", "builtins", "next:npm", "--package", "packages/cli/package.json"]
+`,
+		".bumper/bump-feature.md":   "---\nsdk: minor\n---\n\nAdded a feature\n",
+		"CHANGELOG.md":              "# Changelog\n",
+		"packages/sdk/package.json": "{\n  \"name\": \"@acme/sdk\",\n  \"version\": \"1.2.3\"\n}\n",
+		"packages/cli/package.json": `{
+  "name": "@acme/cli",
+  "version": "0.4.0",
+  "dependencies": {
+    "@acme/sdk": "^1.2.3"
+  },
+  "devDependencies": {
+    "@acme/sdk-testing": "workspace:*"
+  }
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	root := newRootCommand(slog.New(slog.DiscardHandler))
+	root.Writer = io.Discard
+	if err := root.Run(t.Context(), []string{"bumper", "commit", "--dir", dir}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "packages", "cli", "package.json"))
+	if err != nil {
+		t.Fatalf("read cli package.json: %v", err)
+	}
+	want := `{
+  "name": "@acme/cli",
+  "version": "0.4.1",
+  "dependencies": {
+    "@acme/sdk": "^1.3.0"
+  },
+  "devDependencies": {
+    "@acme/sdk-testing": "workspace:*"
+  }
+}
+`
+	if string(got) != want {
+		t.Errorf("cli package.json = %s, want %s", got, want)
+	}
+}
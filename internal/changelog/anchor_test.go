@@ -0,0 +1,97 @@
+package changelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAmendAnchors(t *testing.T) {
+	release := Release{DisplayName: "API", Version: "1.1.0", Patch: TextEntries([]string{"Bug fix"})}
+	section := "## API 1.1.0\n\n### Patch Changes\n\n- Bug fix\n"
+
+	tests := []struct {
+		name         string
+		input        string
+		opts         Options
+		want         string
+		wantInserted bool
+	}{
+		{
+			name:         "marker takes precedence over the heading",
+			input:        "# Changelog\n\nIntro.\n\n<!-- bumper:releases -->\n\n## API 1.0.0\n",
+			want:         "# Changelog\n\nIntro.\n\n<!-- bumper:releases -->\n\n" + section + "\n## API 1.0.0\n",
+			wantInserted: true,
+		},
+		{
+			name:         "custom heading",
+			input:        "# Release notes\n\n## API 1.0.0\n",
+			opts:         Options{Anchor: "# Release notes"},
+			want:         "# Release notes\n\n" + section + "\n## API 1.0.0\n",
+			wantInserted: true,
+		},
+		{
+			name:         "empty input starts with the custom heading",
+			input:        "",
+			opts:         Options{Anchor: "# Release notes"},
+			want:         "# Release notes\n\n" + section,
+			wantInserted: true,
+		},
+		{
+			name:  "no anchor",
+			input: "# Release notes\n\n## API 1.0.0\n",
+			want:  "# Release notes\n\n## API 1.0.0\n",
+		},
+		{
+			name:         "append without an anchor",
+			input:        "# Release notes\n\n## API 1.0.0\n",
+			opts:         Options{Append: true},
+			want:         "# Release notes\n\n## API 1.0.0\n\n" + section,
+			wantInserted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			inserted, err := Amend(&got, strings.NewReader(tt.input), release, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inserted != tt.wantInserted {
+				t.Errorf("inserted = %v, want %v", inserted, tt.wantInserted)
+			}
+			if got.String() != tt.want {
+				t.Errorf("output = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAmendKeepAChangelogAnchors(t *testing.T) {
+	release := Release{Version: "1.1.0", Patch: TextEntries([]string{"Bug fix"})}
+	input := "# Project\n\n## [0.1.0] - 2020-01-01\n\n<!-- bumper:releases -->\n\n## [1.0.0] - 2026-09-01\n"
+
+	var got bytes.Buffer
+	inserted, err := AmendKeepAChangelog(&got, strings.NewReader(input), release, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !inserted {
+		t.Error("expected the release to be inserted below the marker")
+	}
+
+	want := "# Project\n\n## [0.1.0] - 2020-01-01\n\n<!-- bumper:releases -->\n\n## [Unreleased]\n\n## [1.1.0] - 0001-01-01\n\n### Fixed\n\n- Bug fix\n\n## [1.0.0] - 2026-09-01\n"
+	if got.String() != want {
+		t.Errorf("output = %q, want %q", got.String(), want)
+	}
+
+	got.Reset()
+	inserted, err = AmendKeepAChangelog(&got, strings.NewReader("# Project\n"), release, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inserted || got.String() != "# Project\n" {
+		t.Errorf("inserted = %v, output = %q, want the input unchanged", inserted, got.String())
+	}
+}
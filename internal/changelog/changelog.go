@@ -1,7 +1,9 @@
 // Package changelog implements the markdown surgery behind the default
 // changelog strategy: inserting a release section under the top-level
 // "# Changelog" heading and extracting the section for a given release.
-// All operations read from and write to streams; callers own file handling.
+// Changelogs are written in bumper's own format or following the Keep a
+// Changelog convention (https://keepachangelog.com). All operations read
+// from and write to streams; callers own file handling.
 package changelog
 
 import (
@@ -9,16 +11,64 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Format is a changelog layout.
+type Format string
+
+const (
+	// FormatDefault titles releases "## <DisplayName> <Version>" and groups
+	// entries under "Major Changes", "Minor Changes" and "Patch Changes".
+	FormatDefault Format = "default"
+	// FormatKeepAChangelog follows https://keepachangelog.com: releases are
+	// titled "## [<Version>] - <Date>" below an "Unreleased" section, with
+	// compare links at the bottom of the file.
+	FormatKeepAChangelog Format = "keepachangelog"
+)
+
+// ParseFormat returns the format named s. An empty name is FormatDefault.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return FormatDefault, nil
+	case FormatDefault, FormatKeepAChangelog:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown changelog format %q (expected %q or %q)", s, FormatDefault, FormatKeepAChangelog)
+	}
+}
+
+// ReleaseTime returns the time to record for a release: sourceDateEpoch, a
+// SOURCE_DATE_EPOCH value in Unix seconds, when set, for reproducible
+// builds, and otherwise now. The result is in UTC so that the date does not
+// depend on the machine's time zone.
+func ReleaseTime(sourceDateEpoch string, now time.Time) (time.Time, error) {
+	if sourceDateEpoch == "" {
+		return now.UTC(), nil
+	}
+
+	seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: expected Unix seconds", sourceDateEpoch)
+	}
+
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
 // Release describes a release section to insert into a changelog.
 type Release struct {
 	DisplayName string
 	Version     string
-	Major       []string
-	Minor       []string
-	Patch       []string
+	// Date is the release time. Its date is shown in the section heading;
+	// the default format omits it when zero. See ReleaseTime.
+	Date  time.Time
+	Major []Entry
+	Minor []Entry
+	Patch []Entry
 }
 
 // heading is the top-level heading a changelog must start with; release
@@ -29,56 +79,128 @@ const heading = "# Changelog"
 // entry continuation indentation is applied to blank lines.
 var whitespaceOnlyLine = regexp.MustCompile(`(?m)^\s+$`)
 
-// Amend copies the changelog from r to w, inserting the release's section
-// directly under the "# Changelog" heading. Empty input is initialized with
-// the heading first. Input that has content but no heading is copied through
-// unchanged, without the new section.
-func Amend(w io.Writer, r io.Reader, release Release) error {
-	section := release.render()
+// ReleasesMarker is a comment that marks where releases are inserted. When a
+// changelog contains it, it takes precedence over the heading anchor.
+const ReleasesMarker = "<!-- bumper:releases -->"
+
+// Options customizes how a release is written.
+type Options struct {
+	// Template renders the release section. The format's built-in template
+	// is used when nil.
+	Template *Template
+	// Links configures the compare links of FormatKeepAChangelog.
+	Links Links
+	// Anchor is the start of the line releases are inserted below, such as a
+	// custom heading. It defaults to "# Changelog". A ReleasesMarker line
+	// takes precedence over it.
+	Anchor string
+	// Append appends the release to the end of a changelog that has no
+	// anchor instead of leaving the changelog unchanged.
+	Append bool
+}
 
-	out := bufio.NewWriter(w)
-	scanner := bufio.NewScanner(r)
-	sawInput := false
-	inserted := false
+// section renders the release with the template in opts, falling back to
+// the built-in template of format.
+func (opts Options) section(format Format, release Release) (string, error) {
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = FormatTemplate(format)
+	}
 
-	for scanner.Scan() {
-		sawInput = true
-		line := scanner.Text()
+	return tmpl.Render(release)
+}
 
-		if _, err := out.WriteString(line + "\n"); err != nil {
-			return fmt.Errorf("write changelog: %w", err)
-		}
+func (opts Options) anchor() string {
+	if opts.Anchor == "" {
+		return heading
+	}
+	return opts.Anchor
+}
 
-		if !inserted && strings.HasPrefix(line, heading) {
-			if _, err := out.WriteString("\n" + section + "\n"); err != nil {
-				return fmt.Errorf("write changelog: %w", err)
-			}
-			inserted = true
-		}
+// findAnchor returns the index of the line releases are inserted below, or
+// -1 when lines has none.
+func (opts Options) findAnchor(lines []string) int {
+	if i := slices.IndexFunc(lines, func(line string) bool {
+		return strings.TrimSpace(line) == ReleasesMarker
+	}); i >= 0 {
+		return i
 	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("read changelog: %w", err)
+
+	anchor := opts.anchor()
+	return slices.IndexFunc(lines, func(line string) bool {
+		return strings.HasPrefix(line, anchor)
+	})
+}
+
+// Amend copies the changelog from r to w, inserting the release's section
+// directly below the anchor line (see Options.Anchor and ReleasesMarker).
+// Empty input is initialized with the anchor first. Input that has content
+// but no anchor is copied through unchanged, without the new section, unless
+// opts.Append is set. Amend reports whether the section was written.
+func Amend(w io.Writer, r io.Reader, release Release, opts Options) (bool, error) {
+	section, err := opts.section(FormatDefault, release)
+	if err != nil {
+		return false, err
+	}
+
+	lines, err := readLines(r)
+	if err != nil {
+		return false, err
 	}
 
-	if !sawInput {
-		if _, err := out.WriteString(heading + "\n\n" + section + "\n"); err != nil {
-			return fmt.Errorf("write changelog: %w", err)
+	var b strings.Builder
+	inserted := true
+	switch at := opts.findAnchor(lines); {
+	case len(lines) == 0:
+		b.WriteString(opts.anchor() + "\n\n" + section + "\n")
+	case at >= 0:
+		writeLines(&b, lines[:at+1])
+		b.WriteString("\n" + section + "\n")
+		writeLines(&b, lines[at+1:])
+	case opts.Append:
+		writeLines(&b, lines)
+		if strings.TrimSpace(lines[len(lines)-1]) != "" {
+			b.WriteString("\n")
 		}
+		b.WriteString(section + "\n")
+	default:
+		writeLines(&b, lines)
+		inserted = false
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return false, fmt.Errorf("write changelog: %w", err)
 	}
 
-	if err := out.Flush(); err != nil {
-		return fmt.Errorf("flush changelog: %w", err)
+	return inserted, nil
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read changelog: %w", err)
 	}
 
-	return nil
+	return lines, nil
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
 }
 
 // Section reads the changelog from r and returns the section belonging to the
-// release titled "## <displayName> <version>", without surrounding blank
-// lines. It returns an empty string when the release is not present.
+// release titled "## <displayName> <version>" or, in the Keep a Changelog
+// format, "## [<version>]", without surrounding blank lines. Headings may
+// carry a suffix such as the release date. It returns an
+// empty string when the release is not present.
 func Section(r io.Reader, displayName string, version string) (string, error) {
-	title := fmt.Sprintf("## %s %s", displayName, version)
-
 	scanner := bufio.NewScanner(r)
 	collecting := false
 	var output strings.Builder
@@ -86,10 +208,10 @@ func Section(r io.Reader, displayName string, version string) (string, error) {
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		if collecting && strings.HasPrefix(line, "## ") {
+		if collecting && (strings.HasPrefix(line, "## ") || linkDefinition.MatchString(line)) {
 			break
 		}
-		if !collecting && !strings.HasPrefix(line, title) {
+		if !collecting && !isReleaseHeading(line, displayName, version) {
 			continue
 		}
 
@@ -104,33 +226,17 @@ func Section(r io.Reader, displayName string, version string) (string, error) {
 	return strings.TrimSpace(output.String()), nil
 }
 
-func (r Release) render() string {
-	var b strings.Builder
-	fmt.Fprintf(&b, "## %s %s\n\n", r.DisplayName, r.Version)
-
-	levels := []struct {
-		title   string
-		entries []string
-	}{
-		{"Major Changes", r.Major},
-		{"Minor Changes", r.Minor},
-		{"Patch Changes", r.Patch},
-	}
-	for _, level := range levels {
-		if len(level.entries) == 0 {
-			continue
-		}
-
-		b.WriteString("### ")
-		b.WriteString(level.title)
-		b.WriteString("\n\n")
-		for _, entry := range level.entries {
-			b.WriteString(formatEntry(entry))
-		}
-		b.WriteString("\n")
+// isReleaseHeading reports whether line is the heading of the release in
+// either format.
+func isReleaseHeading(line string, displayName string, version string) bool {
+	if label, _, ok := parseKeepAChangelogHeading(line); ok {
+		return label == version
 	}
 
-	return strings.TrimSpace(b.String())
+	title := fmt.Sprintf("## %s %s", displayName, version)
+	rest, ok := strings.CutPrefix(line, title)
+
+	return ok && (rest == "" || strings.HasPrefix(rest, " "))
 }
 
 // formatEntry renders one changelog entry as a markdown list item, indenting
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 var update = flag.Bool("update", false, "rewrite golden files with actual output")
@@ -36,9 +37,9 @@ func TestAmendGolden(t *testing.T) {
 			release: Release{
 				DisplayName: "API",
 				Version:     "1.0.0",
-				Major:       []string{"Breaking change"},
-				Minor:       []string{"New feature"},
-				Patch:       []string{"Bug fix"},
+				Major:       TextEntries([]string{"Breaking change"}),
+				Minor:       TextEntries([]string{"New feature"}),
+				Patch:       TextEntries([]string{"Bug fix"}),
 			},
 		},
 		{
@@ -48,7 +49,7 @@ func TestAmendGolden(t *testing.T) {
 			release: Release{
 				DisplayName: "API",
 				Version:     "1.0.1",
-				Patch:       []string{"7ecb5a4: Fixed a bug"},
+				Patch:       []Entry{{Text: "7ecb5a4: Fixed a bug", Message: "Fixed a bug", SHA: "7ecb5a4"}},
 			},
 		},
 		{
@@ -58,8 +59,19 @@ func TestAmendGolden(t *testing.T) {
 			release: Release{
 				DisplayName: "API",
 				Version:     "2.0.0",
-				Major:       []string{"Dropped legacy support.\nMigration guide:\nhttps://example.com/migrate"},
-				Patch:       []string{"Fixed a race\n\nwith a blank line in the entry"},
+				Major:       TextEntries([]string{"Dropped legacy support.\nMigration guide:\nhttps://example.com/migrate"}),
+				Patch:       TextEntries([]string{"Fixed a race\n\nwith a blank line in the entry"}),
+			},
+		},
+		{
+			name:   "release date in the heading",
+			input:  "amend-existing.input.md",
+			golden: "amend-dated.golden.md",
+			release: Release{
+				DisplayName: "API",
+				Version:     "1.1.0",
+				Date:        time.Date(2026, time.October, 17, 23, 30, 0, 0, time.UTC),
+				Minor:       TextEntries([]string{"New feature"}),
 			},
 		},
 		{
@@ -69,7 +81,7 @@ func TestAmendGolden(t *testing.T) {
 			release: Release{
 				DisplayName: "API",
 				Version:     "1.0.0",
-				Patch:       []string{"Bug fix"},
+				Patch:       TextEntries([]string{"Bug fix"}),
 			},
 		},
 	}
@@ -79,9 +91,85 @@ func TestAmendGolden(t *testing.T) {
 			input := readFixture(t, tt.input)
 
 			var got bytes.Buffer
-			if err := Amend(&got, strings.NewReader(input), tt.release); err != nil {
+			inserted, err := Amend(&got, strings.NewReader(input), tt.release, Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := tt.golden != tt.input; inserted != want {
+				t.Errorf("inserted = %v, want %v", inserted, want)
+			}
+
+			if *update && tt.golden != tt.input {
+				if err := os.WriteFile(filepath.Join("testdata", tt.golden), got.Bytes(), 0o644); err != nil {
+					t.Fatalf("update golden %s: %v", tt.golden, err)
+				}
+			}
+
+			want := readFixture(t, tt.golden)
+			if got.String() != want {
+				t.Errorf("output mismatch\n--- got ---\n%s\n--- want ---\n%s", got.String(), want)
+			}
+		})
+	}
+}
+
+func TestAmendKeepAChangelogGolden(t *testing.T) {
+	release := Release{
+		DisplayName: "API",
+		Version:     "1.1.0",
+		Date:        time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC),
+		Major:       TextEntries([]string{"Renamed the config file"}),
+		Minor:       []Entry{{Text: "abc1234: New feature", Message: "New feature", SHA: "abc1234"}},
+		Patch:       TextEntries([]string{"Bug fix"}),
+	}
+	links := Links{
+		CompareURL: "https://github.com/acme/api/compare/{{from}}...{{to}}",
+		Ref:        func(version string) string { return "v" + version },
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		golden string
+		links  Links
+	}{
+		{
+			name:   "empty changelog gets the preamble and links",
+			input:  "amend-empty.input.md",
+			golden: "keepachangelog-empty.golden.md",
+			links:  links,
+		},
+		{
+			name:   "release goes below unreleased and links are replaced",
+			input:  "keepachangelog-existing.input.md",
+			golden: "keepachangelog-existing.golden.md",
+			links:  links,
+		},
+		{
+			name:   "missing unreleased section is added without links",
+			input:  "keepachangelog-no-unreleased.input.md",
+			golden: "keepachangelog-no-unreleased.golden.md",
+		},
+		{
+			name:   "input without a changelog heading is copied through unchanged",
+			input:  "amend-no-heading.input.md",
+			golden: "amend-no-heading.input.md",
+			links:  links,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := readFixture(t, tt.input)
+
+			var got bytes.Buffer
+			inserted, err := AmendKeepAChangelog(&got, strings.NewReader(input), release, Options{Links: tt.links})
+			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			if want := tt.golden != tt.input; inserted != want {
+				t.Errorf("inserted = %v, want %v", inserted, want)
+			}
 
 			if *update && tt.golden != tt.input {
 				if err := os.WriteFile(filepath.Join("testdata", tt.golden), got.Bytes(), 0o644); err != nil {
@@ -97,6 +185,44 @@ func TestAmendGolden(t *testing.T) {
 	}
 }
 
+func TestSectionKeepAChangelog(t *testing.T) {
+	input := readFixture(t, "keepachangelog-section.input.md")
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{
+			name:    "dated release",
+			version: "1.1.0",
+			want:    "## [1.1.0] - 2026-10-17\n\n### Added\n\n- Second feature",
+		},
+		{
+			name:    "last release stops at the link definitions",
+			version: "1.0.0",
+			want:    "## [1.0.0]\n\n### Added\n\n- New feature",
+		},
+		{
+			name:    "missing version",
+			version: "1.0",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Section(strings.NewReader(input), "API", tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("section = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSection(t *testing.T) {
 	input := readFixture(t, "section.input.md")
 
@@ -106,6 +232,12 @@ func TestSection(t *testing.T) {
 		version     string
 		want        string
 	}{
+		{
+			name:        "heading with a date suffix",
+			displayName: "API",
+			version:     "1.2.0",
+			want:        "## API 1.2.0 (2026-10-17)\n\n### Minor Changes\n\n- Dated feature",
+		},
 		{
 			name:        "first release",
 			displayName: "API",
@@ -130,6 +262,12 @@ func TestSection(t *testing.T) {
 			version:     "9.9.9",
 			want:        "",
 		},
+		{
+			name:        "version prefix of another release",
+			displayName: "API",
+			version:     "1.0",
+			want:        "",
+		},
 		{
 			name:        "wrong display name",
 			displayName: "Web",
@@ -151,6 +289,30 @@ func TestSection(t *testing.T) {
 	}
 }
 
+func TestReleaseTime(t *testing.T) {
+	now := time.Date(2026, time.October, 17, 1, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+
+	got, err := ReleaseTime("", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, time.October, 17, 8, 0, 0, 0, time.UTC); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("release time = %v, want now in UTC", got)
+	}
+
+	got, err = ReleaseTime("1760659200", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2025, time.October, 17, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("release time = %v, want %v", got, want)
+	}
+
+	if _, err := ReleaseTime("yesterday", now); err == nil {
+		t.Error("expected an error for an invalid SOURCE_DATE_EPOCH")
+	}
+}
+
 func TestFormatEntry(t *testing.T) {
 	tests := []struct {
 		name  string
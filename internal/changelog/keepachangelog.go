@@ -0,0 +1,185 @@
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// keepAChangelogPreamble starts a new Keep a Changelog file.
+const keepAChangelogPreamble = heading + `
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).`
+
+// unreleased is the label of the Keep a Changelog section collecting changes
+// that are not released yet.
+const unreleased = "Unreleased"
+
+// keepAChangelogHeading matches "## [1.2.0] - 2026-10-17", "## [1.2.0]" and
+// "## [Unreleased]".
+var keepAChangelogHeading = regexp.MustCompile(`^## \[([^\]]+)\](?:\s+-\s+(.+?))?\s*$`)
+
+// linkDefinition matches a markdown link reference definition such as
+// "[1.2.0]: https://example.com/compare/v1.1.0...v1.2.0".
+var linkDefinition = regexp.MustCompile(`^\[([^\]]+)\]:\s`)
+
+// Links configures the compare links at the bottom of a Keep a Changelog
+// file.
+type Links struct {
+	// CompareURL is the URL of the changes between two git refs, with
+	// {{from}} and {{to}} placeholders, e.g.
+	// "https://github.com/acme/api/compare/{{from}}...{{to}}". Existing links
+	// are left as they are when it is empty.
+	CompareURL string
+	// Ref returns the git ref of a released version, such as its tag.
+	Ref func(version string) string
+}
+
+func (l Links) compare(from string, to string) string {
+	return strings.NewReplacer("{{from}}", from, "{{to}}", to).Replace(l.CompareURL)
+}
+
+// AmendKeepAChangelog copies the Keep a Changelog file from r to w, inserting
+// the release's section below the "## [Unreleased]" section, which is added
+// when missing, and above the previous release. Only lines below the anchor
+// (see Options.Anchor and ReleasesMarker) are considered. Empty input is
+// initialized with the usual preamble first. When opts.Links has a
+// CompareURL, the links for "Unreleased" and the new release are added to the
+// link definitions at the bottom of the file, replacing any existing ones.
+// Input that has content but no anchor is copied through unchanged unless
+// opts.Append is set. AmendKeepAChangelog reports whether the section was
+// written.
+func AmendKeepAChangelog(w io.Writer, r io.Reader, release Release, opts Options) (bool, error) {
+	section, err := opts.section(FormatKeepAChangelog, release)
+	if err != nil {
+		return false, err
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return false, fmt.Errorf("read changelog: %w", err)
+	}
+
+	text := string(content)
+	if text == "" {
+		text = opts.anchor() + strings.TrimPrefix(keepAChangelogPreamble, heading)
+	}
+
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	start := opts.findAnchor(lines)
+	if start < 0 && !opts.Append {
+		if _, err := io.WriteString(w, string(content)); err != nil {
+			return false, fmt.Errorf("write changelog: %w", err)
+		}
+		return false, nil
+	}
+
+	end := linksStart(lines)
+	insertAt, hasUnreleased, previous := end, false, ""
+	for i := start + 1; i < end; i++ {
+		label, _, ok := parseKeepAChangelogHeading(lines[i])
+		switch {
+		case !ok:
+			continue
+		case strings.EqualFold(label, unreleased):
+			hasUnreleased = true
+			continue
+		}
+
+		insertAt, previous = i, label
+		break
+	}
+
+	parts := []string{joinLines(lines[:insertAt])}
+	if !hasUnreleased {
+		parts = append(parts, "## ["+unreleased+"]")
+	}
+	parts = append(parts, section, joinLines(lines[insertAt:end]))
+
+	definitions := lines[end:]
+	if opts.Links.CompareURL != "" {
+		definitions = release.linkDefinitions(definitions, previous, opts.Links)
+	}
+	parts = append(parts, joinLines(definitions))
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(part)
+	}
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return false, fmt.Errorf("write changelog: %w", err)
+	}
+
+	return true, nil
+}
+
+// linkDefinitions puts the links of "Unreleased" and the release on top of
+// definitions, dropping any earlier definitions for either label. The release
+// links to its changes since previous, when there is one.
+func (r Release) linkDefinitions(definitions []string, previous string, links Links) []string {
+	ref := links.Ref
+	if ref == nil {
+		ref = func(version string) string { return version }
+	}
+
+	result := []string{fmt.Sprintf("[%s]: %s", strings.ToLower(unreleased), links.compare(ref(r.Version), "HEAD"))}
+	if previous != "" {
+		result = append(result, fmt.Sprintf("[%s]: %s", r.Version, links.compare(ref(previous), ref(r.Version))))
+	}
+
+	for _, line := range definitions {
+		if m := linkDefinition.FindStringSubmatch(line); m != nil && (strings.EqualFold(m[1], unreleased) || m[1] == r.Version) {
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// parseKeepAChangelogHeading returns the label, such as a version or
+// "Unreleased", and the date of a Keep a Changelog release heading.
+func parseKeepAChangelogHeading(line string) (label string, date string, ok bool) {
+	m := keepAChangelogHeading.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+
+	return m[1], m[2], true
+}
+
+// linksStart returns the index of the block of link definitions ending the
+// file, or len(lines) when there is none.
+func linksStart(lines []string) int {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		switch {
+		case linkDefinition.MatchString(lines[i]):
+			start = i
+		case strings.TrimSpace(lines[i]) == "":
+		default:
+			return start
+		}
+	}
+
+	return start
+}
+
+// joinLines joins lines without the blank lines around them.
+func joinLines(lines []string) string {
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,235 @@
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Problem is a structural problem found by Lint.
+type Problem struct {
+	// Line is the 1-based line the problem was found on.
+	Line    int
+	Message string
+	// Fixable reports whether Fix repairs the problem.
+	Fixable bool
+}
+
+// document is a changelog split into release sections.
+type document struct {
+	// preamble holds the lines before the first release section, including
+	// the anchor heading.
+	preamble []string
+	// hasHeading reports whether the changelog has an anchor line or a
+	// ReleasesMarker to insert releases at, see Options.Anchor.
+	hasHeading bool
+	sections   []section
+	// links holds the link definitions ending the file.
+	links []string
+}
+
+// section is a "## " release section.
+type section struct {
+	// line is the 1-based line of the section heading.
+	line int
+	// name is the release's display name, empty in the Keep a Changelog
+	// format. Sections are only compared with sections of the same name,
+	// since a changelog may be shared by several release groups.
+	name    string
+	version *semver.Version
+	// unreleased marks the Keep a Changelog "Unreleased" section.
+	unreleased bool
+	lines      []string
+}
+
+// title describes the section's release in problems.
+func (s section) title() string {
+	if s.name == "" {
+		return s.version.Original()
+	}
+	return s.name + " " + s.version.Original()
+}
+
+func parseDocument(r io.Reader, opts Options) (*document, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &document{}
+	end := linksStart(lines)
+	doc.links = lines[end:]
+
+	anchor := opts.anchor()
+	for i, line := range lines[:end] {
+		isAnchor := strings.HasPrefix(line, anchor) || strings.TrimSpace(line) == ReleasesMarker
+		if isAnchor {
+			doc.hasHeading = true
+		}
+
+		// An anchor such as "## Releases" is part of the preamble, not a
+		// release section.
+		if !strings.HasPrefix(line, "## ") || (isAnchor && len(doc.sections) == 0) {
+			if len(doc.sections) == 0 {
+				doc.preamble = append(doc.preamble, line)
+			} else {
+				last := &doc.sections[len(doc.sections)-1]
+				last.lines = append(last.lines, line)
+			}
+			continue
+		}
+
+		doc.sections = append(doc.sections, parseSection(i+1, line))
+	}
+
+	return doc, nil
+}
+
+// parseSection parses a release heading in either format. The version of a
+// heading in the default format is its last field that is a semantic
+// version, which skips suffixes such as the release date.
+func parseSection(line int, text string) section {
+	s := section{line: line, lines: []string{text}}
+
+	if label, _, ok := parseKeepAChangelogHeading(text); ok {
+		if strings.EqualFold(label, unreleased) {
+			s.unreleased = true
+		} else if v, err := semver.StrictNewVersion(label); err == nil {
+			s.version = v
+		}
+		return s
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(text, "## "))
+	for i := len(fields) - 1; i >= 0; i-- {
+		if v, err := semver.StrictNewVersion(fields[i]); err == nil {
+			s.name = strings.Join(fields[:i], " ")
+			s.version = v
+			break
+		}
+	}
+
+	return s
+}
+
+// Lint reads the changelog from r and reports its structural problems in
+// line order: a missing anchor heading (see Options.Anchor), release
+// headings without a version, releases listed more than once and releases
+// listed below an older release of the same name. Empty input has no
+// problems.
+func Lint(r io.Reader, opts Options) ([]Problem, error) {
+	doc, err := parseDocument(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.lint(opts.anchor()), nil
+}
+
+func (d *document) lint(anchor string) []Problem {
+	var problems []Problem
+	if !d.hasHeading && (len(d.sections) > 0 || strings.TrimSpace(strings.Join(d.preamble, "")) != "") {
+		problems = append(problems, Problem{Line: 1, Message: fmt.Sprintf("missing %q heading", anchor), Fixable: true})
+	}
+
+	seen := make(map[string]section)
+	newest := make(map[string]section)
+	for _, s := range d.sections {
+		switch {
+		case s.unreleased:
+			continue
+		case s.version == nil:
+			problems = append(problems, Problem{Line: s.line, Message: fmt.Sprintf("release heading %q has no semantic version", s.lines[0])})
+			continue
+		}
+
+		key := s.name + "\x00" + s.version.String()
+		if first, ok := seen[key]; ok {
+			problems = append(problems, Problem{Line: s.line, Message: fmt.Sprintf("duplicate release %s (first listed on line %d)", s.title(), first.line)})
+			continue
+		}
+		seen[key] = s
+
+		if prev, ok := newest[s.name]; ok && s.version.GreaterThan(prev.version) {
+			problems = append(problems, Problem{Line: s.line, Message: fmt.Sprintf("release %s is listed below older release %s on line %d", s.title(), prev.title(), prev.line), Fixable: true})
+		}
+		if prev, ok := newest[s.name]; !ok || s.version.GreaterThan(prev.version) {
+			newest[s.name] = s
+		}
+	}
+
+	return problems
+}
+
+// Fix copies the changelog from r to w, inserting a missing anchor heading
+// (see Options.Anchor) and reordering the releases of each name from newest to oldest.
+// The releases of a name keep the positions they held, so the releases of
+// different groups sharing a changelog stay interleaved. Sections are
+// separated by a single blank line. Problems Fix cannot repair, such as
+// duplicate releases, are left in place. Empty input is copied through.
+func Fix(w io.Writer, r io.Reader, opts Options) error {
+	doc, err := parseDocument(r, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(doc.preamble) == 0 && len(doc.sections) == 0 && len(doc.links) == 0 {
+		return nil
+	}
+
+	if !doc.hasHeading {
+		doc.preamble = append([]string{opts.anchor(), ""}, doc.preamble...)
+	}
+	doc.sortSections()
+
+	parts := []string{joinLines(doc.preamble)}
+	for _, s := range doc.sections {
+		parts = append(parts, joinLines(s.lines))
+	}
+	parts = append(parts, joinLines(doc.links))
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(part)
+	}
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write changelog: %w", err)
+	}
+
+	return nil
+}
+
+// sortSections orders the versioned sections of each name from newest to
+// oldest within the positions they occupy. Other sections stay in place.
+func (d *document) sortSections() {
+	slots := make(map[string][]int)
+	for i, s := range d.sections {
+		if s.version != nil {
+			slots[s.name] = append(slots[s.name], i)
+		}
+	}
+
+	for _, positions := range slots {
+		sorted := make([]section, 0, len(positions))
+		for _, i := range positions {
+			sorted = append(sorted, d.sections[i])
+		}
+		slices.SortStableFunc(sorted, func(a, b section) int {
+			return b.version.Compare(a.version)
+		})
+		for j, i := range positions {
+			d.sections[i] = sorted[j]
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package changelog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	problems, err := Lint(strings.NewReader(readFixture(t, "lint.input.md")), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Problem{
+		{Line: 1, Message: `missing "# Changelog" heading`, Fixable: true},
+		{Line: 13, Message: "release API 1.1.0 is listed below older release API 1.0.0 on line 1", Fixable: true},
+		{Line: 19, Message: "release Web 2.1.0 is listed below older release Web 2.0.0 on line 7", Fixable: true},
+		{Line: 24, Message: "duplicate release API 1.0.0 (first listed on line 1)"},
+		{Line: 30, Message: `release heading "## Notes" has no semantic version`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems = %+v, want %+v", problems, want)
+	}
+}
+
+func TestLintKeepAChangelog(t *testing.T) {
+	for _, name := range []string{"keepachangelog-existing.golden.md", "keepachangelog-section.input.md"} {
+		problems, err := Lint(strings.NewReader(readFixture(t, name)), Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(problems) != 0 {
+			t.Errorf("%s: problems = %+v, want none", name, problems)
+		}
+	}
+
+	problems, err := Lint(strings.NewReader("# Changelog\n\n## [Unreleased]\n\n## [1.0.0]\n\n## [1.1.0] - 2026-10-17\n"), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Problem{{Line: 7, Message: "release 1.1.0 is listed below older release 1.0.0 on line 5", Fixable: true}}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems = %+v, want %+v", problems, want)
+	}
+}
+
+func TestFixGolden(t *testing.T) {
+	var got bytes.Buffer
+	if err := Fix(&got, strings.NewReader(readFixture(t, "lint.input.md")), Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(filepath.Join("testdata", "lint-fix.golden.md"), got.Bytes(), 0o644); err != nil {
+			t.Fatalf("update golden: %v", err)
+		}
+	}
+
+	if want := readFixture(t, "lint-fix.golden.md"); got.String() != want {
+		t.Errorf("output mismatch\n--- got ---\n%s\n--- want ---\n%s", got.String(), want)
+	}
+
+	problems, err := Lint(bytes.NewReader(got.Bytes()), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, problem := range problems {
+		if problem.Fixable {
+			t.Errorf("fixable problem left after fix: %+v", problem)
+		}
+	}
+}
+
+func TestFixLeavesValidChangelogUnchanged(t *testing.T) {
+	for _, name := range []string{"amend-existing.golden.md", "keepachangelog-existing.golden.md"} {
+		input := readFixture(t, name)
+
+		var got bytes.Buffer
+		if err := Fix(&got, strings.NewReader(input), Options{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.String() != input {
+			t.Errorf("%s: fix changed a valid changelog:\n%s", name, got.String())
+		}
+	}
+}
+
+func TestLintCustomAnchor(t *testing.T) {
+	opts := Options{Anchor: "## Releases"}
+	input := "# Acme API\n\n## Releases\n\n## 1.0.0\n\n- First\n"
+
+	problems, err := Lint(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("problems = %+v, want none", problems)
+	}
+
+	problems, err = Lint(strings.NewReader(input), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Problem{
+		{Line: 1, Message: `missing "# Changelog" heading`, Fixable: true},
+		{Line: 3, Message: `release heading "## Releases" has no semantic version`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems = %+v, want %+v", problems, want)
+	}
+
+	var got bytes.Buffer
+	if err := Fix(&got, strings.NewReader("## 1.0.0\n\n- First\n"), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "## Releases\n\n## 1.0.0\n\n- First\n"; got.String() != want {
+		t.Errorf("fixed changelog = %q, want %q", got.String(), want)
+	}
+}
@@ -0,0 +1,206 @@
+package changelog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate renders release sections in FormatDefault. The release
+// date, when set, follows the version in the heading.
+const DefaultTemplate = `## {{ .DisplayName }} {{ .Version }}{{ if not .Date.IsZero }} ({{ .Date.Format "2006-01-02" }}){{ end }}
+{{ range .Levels }}
+### {{ .Title }}
+
+{{ range .Entries }}{{ item .Text }}{{ end }}{{ end }}`
+
+// KeepAChangelogTemplate renders release sections in FormatKeepAChangelog,
+// listing entries under their Keep a Changelog category, see
+// TemplateData.Categories.
+const KeepAChangelogTemplate = `## [{{ .Version }}] - {{ .Date.Format "2006-01-02" }}
+{{ range .Categories }}
+### {{ .Title }}
+
+{{ range .Entries }}{{ item .Text }}{{ end }}{{ end }}`
+
+// Template renders a release section. Templates are Go text/templates
+// executed with a TemplateData; surrounding whitespace is trimmed from the
+// result.
+type Template struct {
+	tmpl *template.Template
+}
+
+// templateFuncs are available to every template.
+var templateFuncs = template.FuncMap{
+	// item renders an entry as a markdown list item, indenting continuation
+	// lines.
+	"item": formatEntry,
+}
+
+// ParseTemplate parses a release section template. name identifies it in
+// errors.
+func ParseTemplate(name string, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse changelog template: %w", err)
+	}
+
+	return &Template{tmpl: tmpl}, nil
+}
+
+// FormatTemplate returns the built-in template of format.
+func FormatTemplate(format Format) *Template {
+	text := DefaultTemplate
+	if format == FormatKeepAChangelog {
+		text = KeepAChangelogTemplate
+	}
+
+	tmpl, err := ParseTemplate(string(format), text)
+	if err != nil {
+		panic(err)
+	}
+
+	return tmpl
+}
+
+// TemplateData is what a template renders.
+type TemplateData struct {
+	DisplayName string
+	Version     string
+	Date        time.Time
+	Major       []Entry
+	Minor       []Entry
+	Patch       []Entry
+	// Levels lists the levels with entries, major first.
+	Levels []Level
+	// Categories lists the Keep a Changelog categories with entries, in the
+	// order of CategoryNames.
+	Categories []Category
+}
+
+// Level is the entries of one bump level.
+type Level struct {
+	// Name is "major", "minor" or "patch".
+	Name string
+	// Title is the level's heading in the default format, such as "Major
+	// Changes".
+	Title   string
+	Entries []Entry
+}
+
+// Category is the entries of one Keep a Changelog category.
+type Category struct {
+	// Name is the category's name, such as "removed".
+	Name string
+	// Title is the category's heading, such as "Removed".
+	Title   string
+	Entries []Entry
+}
+
+// CategoryNames lists the Keep a Changelog categories in the order releases
+// list them.
+var CategoryNames = []string{"added", "changed", "deprecated", "removed", "fixed", "security"}
+
+// IsCategory reports whether name is one of CategoryNames.
+func IsCategory(name string) bool {
+	return slices.Contains(CategoryNames, name)
+}
+
+// Entry is a changelog entry.
+type Entry struct {
+	// Text is the entry as given, including any commit prefix.
+	Text string
+	// Message is Text without the commit prefix.
+	Message string
+	// SHA is the hash of the commit that added the entry's bump file, or ""
+	// when it is unknown.
+	SHA string
+	// Commit is SHA abbreviated to 7 characters. Templates see it filled in
+	// from SHA.
+	Commit string
+	// Category is the entry's Keep a Changelog category, one of
+	// CategoryNames. Without one, minor entries are listed as added, major
+	// entries as changed and patch entries as fixed.
+	Category string
+}
+
+// TextEntries returns entries of unknown provenance, such as those passed in
+// the --major, --minor and --patch flags, whose message is their text.
+func TextEntries(texts []string) []Entry {
+	entries := make([]Entry, 0, len(texts))
+	for _, text := range texts {
+		entries = append(entries, Entry{Text: text, Message: text})
+	}
+
+	return entries
+}
+
+func templateEntries(entries []Entry) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Message == "" {
+			entry.Message = entry.Text
+		}
+		if entry.Commit == "" {
+			entry.Commit = entry.SHA[:min(7, len(entry.SHA))]
+		}
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+func (r Release) templateData() TemplateData {
+	data := TemplateData{
+		DisplayName: r.DisplayName,
+		Version:     r.Version,
+		Date:        r.Date,
+		Major:       templateEntries(r.Major),
+		Minor:       templateEntries(r.Minor),
+		Patch:       templateEntries(r.Patch),
+	}
+
+	levels := []Level{
+		{Name: "major", Title: "Major Changes", Entries: data.Major},
+		{Name: "minor", Title: "Minor Changes", Entries: data.Minor},
+		{Name: "patch", Title: "Patch Changes", Entries: data.Patch},
+	}
+	for _, level := range levels {
+		if len(level.Entries) > 0 {
+			data.Levels = append(data.Levels, level)
+		}
+	}
+
+	byCategory := make(map[string][]Entry)
+	for _, level := range levels {
+		for _, entry := range level.Entries {
+			category := entry.Category
+			if !IsCategory(category) {
+				category = levelCategories[level.Name]
+			}
+			byCategory[category] = append(byCategory[category], entry)
+		}
+	}
+	for _, name := range CategoryNames {
+		if entries := byCategory[name]; len(entries) > 0 {
+			data.Categories = append(data.Categories, Category{Name: name, Title: strings.ToUpper(name[:1]) + name[1:], Entries: entries})
+		}
+	}
+
+	return data
+}
+
+// levelCategories are the categories of entries without one.
+var levelCategories = map[string]string{"major": "changed", "minor": "added", "patch": "fixed"}
+
+// Render renders the release's section.
+func (t *Template) Render(release Release) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, release.templateData()); err != nil {
+		return "", fmt.Errorf("render changelog template: %w", err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
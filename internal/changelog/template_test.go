@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tmpl, err := ParseTemplate("custom", `## {{ .Version }} ({{ .Date.Format "Jan 2, 2006" }})
+{{ range .Levels }}
+### {{ if eq .Name "major" }}💥{{ else if eq .Name "minor" }}✨{{ else }}🐛{{ end }} {{ .Title }}
+
+{{ range .Entries }}- {{ .Message }}{{ with .Commit }} ([{{ . }}](https://github.com/acme/api/commit/{{ . }})){{ end }}
+{{ end }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := tmpl.Render(Release{
+		DisplayName: "API",
+		Version:     "1.1.0",
+		Date:        time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		Minor:       []Entry{{Text: "abc1234: New feature", Message: "New feature", SHA: "abc1234def5678901234567890abcdef12345678"}},
+		// Without a known commit, text that looks like a commit prefix is
+		// part of the message.
+		Patch: TextEntries([]string{"cafe123: Bug fix"}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `## 1.1.0 (Oct 17, 2026)
+
+### ✨ Minor Changes
+
+- New feature ([abc1234](https://github.com/acme/api/commit/abc1234))
+
+### 🐛 Patch Changes
+
+- cafe123: Bug fix`
+	if got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+}
+
+func TestKeepAChangelogCategories(t *testing.T) {
+	got, err := FormatTemplate(FormatKeepAChangelog).Render(Release{
+		Version: "2.0.0",
+		Date:    time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		Major: []Entry{
+			{Text: "Removed the v1 endpoints", Category: "removed"},
+			{Text: "Renamed the client"},
+		},
+		Minor: TextEntries([]string{"New feature"}),
+		Patch: []Entry{
+			{Text: "Patched a vulnerability", Category: "security"},
+			{Text: "Deprecated the old flag", Category: "deprecated"},
+			{Text: "Bug fix"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `## [2.0.0] - 2026-10-17
+
+### Added
+
+- New feature
+
+### Changed
+
+- Renamed the client
+
+### Deprecated
+
+- Deprecated the old flag
+
+### Removed
+
+- Removed the v1 endpoints
+
+### Fixed
+
+- Bug fix
+
+### Security
+
+- Patched a vulnerability`
+	if got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+}
+
+func TestAmendWithTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("custom", "## {{ .DisplayName }} v{{ .Version }}\n\n{{ range .Patch }}{{ item .Text }}{{ end }}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got bytes.Buffer
+	release := Release{DisplayName: "API", Version: "1.0.1", Patch: TextEntries([]string{"Bug fix"})}
+	if _, err := Amend(&got, strings.NewReader("# Changelog\n"), release, Options{Template: tmpl}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "# Changelog\n\n## API v1.0.1\n\n- Bug fix\n"
+	if got.String() != want {
+		t.Errorf("output = %q, want %q", got.String(), want)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := ParseTemplate("broken", "{{ .Version "); err == nil {
+		t.Error("expected a parse error")
+	}
+
+	tmpl, err := ParseTemplate("unknown field", "{{ .Tag }}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := Amend(&out, strings.NewReader("# Changelog\n"), Release{Version: "1.0.0"}, Options{Template: tmpl}); err == nil {
+		t.Error("expected a render error")
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %q before failing", out.String())
+	}
+}
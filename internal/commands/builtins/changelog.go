@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/disintegrator/bumper/internal/changelog"
 	"github.com/disintegrator/bumper/internal/cmd"
@@ -18,34 +19,35 @@ import (
 
 // newChangelogPathFlag returns the --path flag shared by the default
 // changelog commands. It carries no static default: both commands fall back
-// to CHANGELOG.md in the workspace root, which is only known after the
-// workspace is resolved.
-func newChangelogPathFlag() *cli.StringFlag {
+// to the release group's changelog_path, or CHANGELOG.md, in the workspace
+// root, which is only known after the workspace is resolved.
+func newChangelogPathFlag(env workspace.Environ) *cli.StringFlag {
 	return &cli.StringFlag{
 		Name:      "path",
-		Usage:     "The path to the changelog file (defaults to CHANGELOG.md in the workspace root)",
-		Sources:   cli.EnvVars("BUMPER_CHANGELOG_PATH"),
+		Usage:     "The path to the changelog file (defaults to the group's changelog_path or CHANGELOG.md in the workspace root)",
+		Sources:   envVars(env, "BUMPER_CHANGELOG_PATH"),
 		TakesFile: true,
 	}
 }
 
-func changelogPath(c *cli.Command, workspaceDir string) string {
+func changelogPath(ctx context.Context, c *cli.Command, workspaceDir string, group workspace.ReleaseGroup) string {
 	if path := c.String("path"); path != "" {
-		return path
+		return workspace.EnvironFrom(ctx).Path(path)
 	}
-	return filepath.Join(workspaceDir, "CHANGELOG.md")
+	return group.ChangelogFilename(workspaceDir)
 }
 
-func newDefaultAmendChangelogCommand(logger *slog.Logger) *cli.Command {
+func newDefaultAmendChangelogCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:                      "amendlog:default",
 		Usage:                     "Get the current version of a release group using the default strategy",
 		DisableSliceFlagSeparator: true,
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
-			newChangelogPathFlag(),
-			releaseGroupFlag,
-			nextVersionFlag,
+			shared.NewLockTimeoutFlag(),
+			newChangelogPathFlag(env),
+			newReleaseGroupFlag(env),
+			newNextVersionFlag(env),
 			&cli.StringSliceFlag{
 				Name:  "major",
 				Usage: "Major changes in the given version (repeatable flag)",
@@ -58,6 +60,34 @@ func newDefaultAmendChangelogCommand(logger *slog.Logger) *cli.Command {
 				Name:  "patch",
 				Usage: "Patch changes in the given version (repeatable flag)",
 			},
+			&cli.StringFlag{
+				Name:      "template",
+				Usage:     "The Go template rendering the release section (defaults to the group's changelog_template)",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:    "timestamp",
+				Usage:   "The release time in Unix seconds, shown as a date in the section heading (defaults to now)",
+				Sources: envVars(env, "SOURCE_DATE_EPOCH"),
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "The changelog format: default or keepachangelog (defaults to the group's changelog_format)",
+			},
+			&cli.StringFlag{
+				Name: "compare-url",
+				Usage: "With the keepachangelog format, the URL of the changes between two git refs, with {{from}} and {{to}}" +
+					" placeholders (e.g. https://github.com/acme/api/compare/{{from}}...{{to}}). Adds compare links for" +
+					" each release, using the group's tag_format for refs",
+			},
+			&cli.StringFlag{
+				Name:  "anchor",
+				Usage: "The start of the line releases are inserted below, such as a custom heading (defaults to \"# Changelog\"). A <!-- bumper:releases --> line takes precedence",
+			},
+			&cli.BoolFlag{
+				Name:  "append",
+				Usage: "Append the release to the end of a changelog without an insertion anchor instead of failing",
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			groupName := releaseGroup(c)
@@ -66,6 +96,12 @@ func newDefaultAmendChangelogCommand(logger *slog.Logger) *cli.Command {
 				return err
 			}
 
+			lock, err := shared.Lock(ctx, logger, res.Dir, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
 			displayName := groupName
 			group, ok := res.Config.IndexReleaseGroups()[groupName]
 			if ok {
@@ -76,16 +112,68 @@ func newDefaultAmendChangelogCommand(logger *slog.Logger) *cli.Command {
 				logger.WarnContext(ctx, "release group not in config", slog.String("group", groupName), slog.String("config", workspace.ConfigFilename(res.Dir)))
 			}
 
-			filename := changelogPath(c, res.Dir)
+			formatName := c.String("format")
+			if formatName == "" {
+				formatName = group.ChangelogFormat
+			}
+			format, err := changelog.ParseFormat(formatName)
+			if err != nil {
+				logger.ErrorContext(ctx, "invalid changelog format", slog.String("format", formatName), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			date, err := changelog.ReleaseTime(c.String("timestamp"), time.Now())
+			if err != nil {
+				logger.ErrorContext(ctx, "invalid release date", slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			filename := changelogPath(ctx, c, res.Dir, group)
 			release := changelog.Release{
 				DisplayName: displayName,
 				Version:     nextVersion(c),
-				Major:       c.StringSlice("major"),
-				Minor:       c.StringSlice("minor"),
-				Patch:       c.StringSlice("patch"),
+				Date:        date,
+				Major:       changelog.TextEntries(c.StringSlice("major")),
+				Minor:       changelog.TextEntries(c.StringSlice("minor")),
+				Patch:       changelog.TextEntries(c.StringSlice("patch")),
+			}
+			// bumper commit passes the entries as structured input too,
+			// which takes precedence over the flags.
+			if inputFile, _ := env.LookupEnv(workspace.ChangelogInputEnv); inputFile != "" {
+				input, err := workspace.ReadChangelogInput(inputFile)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to read changelog input", slog.String("file", inputFile), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+				release.Major = releaseEntries(input.Major)
+				release.Minor = releaseEntries(input.Minor)
+				release.Patch = releaseEntries(input.Patch)
+			}
+			// Compare links point at the release tags, which are "v<version>"
+			// for groups without a tag_format, as with current:git-tag.
+			tagged := group
+			if tagged.TagFormat == "" {
+				tagged.TagFormat = workspace.DefaultTagFormat
+			}
+			opts := changelog.Options{
+				Links:  changelog.Links{CompareURL: c.String("compare-url"), Ref: tagged.TagName},
+				Anchor: c.String("anchor"),
+				Append: c.Bool("append"),
 			}
 
-			if err := amendChangelogFile(filename, release); err != nil {
+			templateFile := workspace.EnvironFrom(ctx).Path(c.String("template"))
+			if templateFile == "" {
+				templateFile = group.ChangelogTemplateFilename(res.Dir)
+			}
+			if templateFile != "" {
+				opts.Template, err = loadTemplate(templateFile)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to load changelog template", slog.String("file", templateFile), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+			}
+
+			if err := amendChangelogFile(filename, format, release, opts); err != nil {
 				logger.ErrorContext(ctx, "failed to amend changelog", slog.String("file", filename), slog.String("error", err.Error()))
 				return cmd.Failed(err)
 			}
@@ -95,35 +183,72 @@ func newDefaultAmendChangelogCommand(logger *slog.Logger) *cli.Command {
 	}
 }
 
+// releaseEntries converts the entries of the changelog input, keeping their
+// commit and category for templates.
+func releaseEntries(entries []workspace.ChangelogEntry) []changelog.Entry {
+	result := make([]changelog.Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, changelog.Entry{Text: entry.Content, Message: entry.Message, SHA: entry.SHA, Category: entry.Category})
+	}
+	return result
+}
+
 // amendChangelogFile opens the changelog for the stream-based amendment,
-// treating a missing file as an empty changelog.
-func amendChangelogFile(filename string, release changelog.Release) error {
+// treating a missing file as an empty changelog and creating its directory.
+// The file is left untouched when it has no insertion anchor.
+func amendChangelogFile(filename string, format changelog.Format, release changelog.Release, opts changelog.Options) error {
 	content, err := os.ReadFile(filename)
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("read changelog file: %w", err)
 	}
 
 	var amended bytes.Buffer
-	if err := changelog.Amend(&amended, bytes.NewReader(content), release); err != nil {
+	var inserted bool
+	switch format {
+	case changelog.FormatKeepAChangelog:
+		inserted, err = changelog.AmendKeepAChangelog(&amended, bytes.NewReader(content), release, opts)
+	default:
+		inserted, err = changelog.Amend(&amended, bytes.NewReader(content), release, opts)
+	}
+	if err != nil {
 		return err
 	}
+	if !inserted {
+		anchor := opts.Anchor
+		if anchor == "" {
+			anchor = "# Changelog"
+		}
+		return fmt.Errorf("no line starting with %q or %s to insert the release below (use --append to add it to the end)", anchor, changelog.ReleasesMarker)
+	}
 
-	if err := os.WriteFile(filename, amended.Bytes(), 0644); err != nil {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("create changelog directory: %w", err)
+	}
+	if err := workspace.WriteFileAtomic(filename, amended.Bytes(), 0644); err != nil {
 		return fmt.Errorf("write changelog file: %w", err)
 	}
 
 	return nil
 }
 
-func newDefaultCatChangelogCommand(logger *slog.Logger) *cli.Command {
+func loadTemplate(filename string) (*changelog.Template, error) {
+	text, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read changelog template: %w", err)
+	}
+
+	return changelog.ParseTemplate(filepath.Base(filename), string(text))
+}
+
+func newDefaultCatChangelogCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "cat:default",
 		Usage: "Get the release notes of a release group using the default strategy",
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
-			newChangelogPathFlag(),
-			releaseGroupFlag,
-			versionFlag,
+			newChangelogPathFlag(env),
+			newReleaseGroupFlag(env),
+			newVersionFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			groupName := releaseGroup(c)
@@ -143,7 +268,7 @@ func newDefaultCatChangelogCommand(logger *slog.Logger) *cli.Command {
 				displayName = group.DisplayName
 			}
 
-			logfile := changelogPath(c, res.Dir)
+			logfile := changelogPath(ctx, c, res.Dir, group)
 			file, err := os.Open(logfile)
 			if err != nil {
 				logger.ErrorContext(ctx, "failed to open changelog", slog.String("file", logfile), slog.String("error", err.Error()))
@@ -161,7 +286,85 @@ func newDefaultCatChangelogCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(fmt.Errorf("no release notes found for version %s in group %s", versionStr, groupName))
 			}
 
-			fmt.Println(result)
+			fmt.Fprintln(c.Root().Writer, result)
+
+			return nil
+		},
+	}
+}
+
+func newLintChangelogCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
+	return &cli.Command{
+		Name:  "lint:changelog",
+		Usage: "Report structural problems in a changelog, such as duplicate or out-of-order releases",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			newChangelogPathFlag(env),
+			&cli.StringFlag{
+				Name:    "group",
+				Usage:   "Release group whose changelog_path to lint",
+				Sources: envVars(env, "BUMPER_GROUP"),
+			},
+			&cli.StringFlag{
+				Name:  "anchor",
+				Usage: "The start of the heading releases are listed below, as passed to amendlog:default (defaults to \"# Changelog\"). A <!-- bumper:releases --> line also counts",
+			},
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "Insert a missing anchor heading and reorder releases from newest to oldest",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			var group workspace.ReleaseGroup
+			if name := releaseGroup(c); name != "" {
+				group, err = res.Group(ctx, logger, name)
+				if err != nil {
+					return err
+				}
+			}
+
+			filename := changelogPath(ctx, c, res.Dir, group)
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to read changelog", slog.String("file", filename), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			opts := changelog.Options{Anchor: c.String("anchor")}
+			if c.Bool("fix") {
+				var fixed bytes.Buffer
+				if err := changelog.Fix(&fixed, bytes.NewReader(content), opts); err != nil {
+					logger.ErrorContext(ctx, "failed to fix changelog", slog.String("file", filename), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+				if !bytes.Equal(fixed.Bytes(), content) {
+					if err := workspace.WriteFileAtomic(filename, fixed.Bytes(), 0644); err != nil {
+						logger.ErrorContext(ctx, "failed to write changelog", slog.String("file", filename), slog.String("error", err.Error()))
+						return cmd.Failed(err)
+					}
+					logger.InfoContext(ctx, "fixed changelog", slog.String("file", filename))
+				}
+				content = fixed.Bytes()
+			}
+
+			problems, err := changelog.Lint(bytes.NewReader(content), opts)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to lint changelog", slog.String("file", filename), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			out := c.Root().Writer
+			for _, problem := range problems {
+				fmt.Fprintf(out, "%s:%d: %s\n", filename, problem.Line, problem.Message)
+			}
+			if len(problems) > 0 {
+				return cmd.Failed(fmt.Errorf("changelog %s has structural problems", filename))
+			}
 
 			return nil
 		},
@@ -3,31 +3,40 @@ package builtins
 import (
 	"log/slog"
 
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
 )
 
 func NewCommand(logger *slog.Logger) *cli.Command {
+	return newCommand(logger, workspace.Environ{})
+}
+
+// newCommand returns the builtins' command reading its flags' environment
+// variables from env.
+func newCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:    "builtins",
 		Aliases: []string{"b"},
 		Usage:   "Built-in commands for working with versions and changelogs",
 		Commands: []*cli.Command{
-			newDefaultCurrentCommand(logger),
-			newFileCurrentCommand(logger),
-			newNPMCurrentCommand(logger),
-			newTOMLCurrentCommand(logger),
-			newJSONCurrentCommand(logger),
-			newYAMLCurrentCommand(logger),
+			newDefaultCurrentCommand(logger, env),
+			newFileCurrentCommand(logger, env),
+			newNPMCurrentCommand(logger, env),
+			newTOMLCurrentCommand(logger, env),
+			newJSONCurrentCommand(logger, env),
+			newYAMLCurrentCommand(logger, env),
+			newGitTagCurrentCommand(logger, env),
 
-			newDefaultNextCommand(logger),
-			newFileNextCommand(logger),
-			newNPMNextCommand(logger),
-			newTOMLNextCommand(logger),
-			newJSONNextCommand(logger),
-			newYAMLNextCommand(logger),
+			newDefaultNextCommand(logger, env),
+			newFileNextCommand(logger, env),
+			newNPMNextCommand(logger, env),
+			newTOMLNextCommand(logger, env),
+			newJSONNextCommand(logger, env),
+			newYAMLNextCommand(logger, env),
 
-			newDefaultAmendChangelogCommand(logger),
-			newDefaultCatChangelogCommand(logger),
+			newDefaultAmendChangelogCommand(logger, env),
+			newDefaultCatChangelogCommand(logger, env),
+			newLintChangelogCommand(logger, env),
 		},
 	}
 }
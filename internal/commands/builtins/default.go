@@ -1,6 +1,7 @@
 package builtins
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -15,13 +16,13 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
-func newDefaultCurrentCommand(logger *slog.Logger) *cli.Command {
+func newDefaultCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "current:default",
 		Usage: "Get the current version of a release group using the default strategy",
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
-			releaseGroupFlag,
+			newReleaseGroupFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
@@ -51,21 +52,22 @@ func newDefaultCurrentCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			fmt.Print(sv.String())
+			fmt.Fprint(c.Root().Writer, sv.String())
 
 			return nil
 		},
 	}
 }
 
-func newDefaultNextCommand(logger *slog.Logger) *cli.Command {
+func newDefaultNextCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "next:default",
 		Usage: "Set the version of a release group using the default strategy",
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
-			releaseGroupFlag,
-			nextVersionFlag,
+			shared.NewLockTimeoutFlag(),
+			newReleaseGroupFlag(env),
+			newNextVersionFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
@@ -73,6 +75,12 @@ func newDefaultNextCommand(logger *slog.Logger) *cli.Command {
 				return err
 			}
 
+			lock, err := shared.Lock(ctx, logger, res.Dir, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
 			var versions struct {
 				Values map[string]string `toml:"versions,omitempty,omitzero"`
 			}
@@ -89,16 +97,13 @@ func newDefaultNextCommand(logger *slog.Logger) *cli.Command {
 			}
 			versions.Values[releaseGroup(c)] = c.String("version")
 
-			f, err := os.Create(versionFile)
-			if err != nil {
-				logger.ErrorContext(ctx, "failed to open versions file for writing", slog.String("file", versionFile), slog.String("error", err.Error()))
+			var buf bytes.Buffer
+			if err := toml.NewEncoder(&buf).Encode(versions); err != nil {
+				logger.ErrorContext(ctx, "failed to encode versions file", slog.String("file", versionFile), slog.String("error", err.Error()))
 				return cmd.Failed(err)
 			}
-			defer f.Close()
 
-			encoder := toml.NewEncoder(f)
-			err = encoder.Encode(versions)
-			if err != nil {
+			if err := workspace.WriteFileAtomic(versionFile, buf.Bytes(), 0644); err != nil {
 				logger.ErrorContext(ctx, "failed to write versions file", slog.String("file", versionFile), slog.String("error", err.Error()))
 				return cmd.Failed(err)
 			}
@@ -10,42 +10,46 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
 )
 
-var (
-	filePathFlag = &cli.StringFlag{
+func newFilePathFlag() *cli.StringFlag {
+	return &cli.StringFlag{
 		Name:      "path",
 		Usage:     "Path to a file containing the version",
 		Required:  true,
 		TakesFile: true,
 	}
+}
 
-	filePathsFlag = &cli.StringSliceFlag{
+func newFilePathsFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
 		Name:      "path",
 		Usage:     "Paths to files containing the version (repeatable flag)",
 		Required:  true,
 		TakesFile: true,
 	}
-)
+}
 
-func filePath(c *cli.Command) string {
-	return c.String("path")
+func filePath(ctx context.Context, c *cli.Command) string {
+	return workspace.EnvironFrom(ctx).Path(c.String("path"))
 }
 
-func filePaths(c *cli.Command) []string {
-	return c.StringSlice("path")
+func filePaths(ctx context.Context, c *cli.Command) []string {
+	return environPaths(ctx, c.StringSlice("path"))
 }
 
-func newFileCurrentCommand(logger *slog.Logger) *cli.Command {
+func newFileCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "current:file",
 		Usage: "Get the current version from a simple text file",
 		Flags: []cli.Flag{
-			filePathFlag,
+			newFilePathFlag(),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			path := filePath(c)
+			path := filePath(ctx, c)
 			data, err := os.ReadFile(path)
 			switch {
 			case errors.Is(err, os.ErrNotExist):
@@ -63,24 +67,31 @@ func newFileCurrentCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			fmt.Print(sv.String())
+			fmt.Fprint(c.Root().Writer, sv.String())
 
 			return nil
 		},
 	}
 }
 
-func newFileNextCommand(logger *slog.Logger) *cli.Command {
+func newFileNextCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "next:file",
 		Usage: "Set the next version in a simple text file",
 		Flags: []cli.Flag{
-			filePathsFlag,
-			nextVersionFlag,
+			shared.NewLockTimeoutFlag(),
+			newFilePathsFlag(),
+			newNextVersionFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			for _, path := range filePaths(c) {
-				err := os.WriteFile(path, []byte(strings.TrimSpace(c.String("version"))), 0644)
+			lock, err := shared.LockWorkingDir(ctx, logger, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
+			for _, path := range filePaths(ctx, c) {
+				err := workspace.WriteFileAtomic(path, []byte(strings.TrimSpace(c.String("version"))), 0644)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to write version file", slog.String("file", path), slog.String("error", err.Error()))
 					return cmd.Failed(err)
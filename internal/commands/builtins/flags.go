@@ -1,49 +1,91 @@
 package builtins
 
 import (
+	"context"
 	"fmt"
 	"slices"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
 )
 
-var (
-	releaseGroupFlag = &cli.StringFlag{
+// envVars is cli.EnvVars for the environment described by env. Flag sources
+// are looked up without a context, so the builtins' commands are built for
+// the environment they run in: the process's for `bumper builtins`, or that
+// of a group command for builtins run in-process.
+func envVars(env workspace.Environ, keys ...string) cli.ValueSourceChain {
+	sources := make([]cli.ValueSource, len(keys))
+	for i, key := range keys {
+		sources[i] = envVarSource{env: env, key: key}
+	}
+	return cli.NewValueSourceChain(sources...)
+}
+
+type envVarSource struct {
+	env workspace.Environ
+	key string
+}
+
+func (s envVarSource) Lookup() (string, bool) { return s.env.LookupEnv(s.key) }
+func (s envVarSource) IsFromEnv() bool        { return true }
+func (s envVarSource) Key() string            { return s.key }
+func (s envVarSource) String() string         { return fmt.Sprintf("environment variable %q", s.key) }
+func (s envVarSource) GoString() string       { return fmt.Sprintf("&envVarSource{Key:%q}", s.key) }
+
+// environPaths resolves relative paths against the working directory of the
+// builtin, see workspace.Environ.
+func environPaths(ctx context.Context, paths []string) []string {
+	env := workspace.EnvironFrom(ctx)
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		resolved[i] = env.Path(path)
+	}
+	return resolved
+}
+
+func newReleaseGroupFlag(env workspace.Environ) *cli.StringFlag {
+	return &cli.StringFlag{
 		Name:     "group",
 		Usage:    "Release group name",
 		Required: true,
-		Sources:  cli.EnvVars("BUMPER_GROUP"),
+		Sources:  envVars(env, "BUMPER_GROUP"),
 	}
+}
 
-	versionFlag = &cli.StringFlag{
+func newVersionFlag(env workspace.Environ) *cli.StringFlag {
+	return &cli.StringFlag{
 		Name:     "version",
 		Usage:    "A version to query for",
 		Required: true,
-		Sources:  cli.EnvVars("BUMPER_GROUP_VERSION"),
+		Sources:  envVars(env, "BUMPER_GROUP_VERSION"),
 		Validator: func(s string) error {
 			_, err := semver.NewVersion(s)
 			return err
 		},
 	}
+}
 
-	nextVersionFlag = &cli.StringFlag{
+func newNextVersionFlag(env workspace.Environ) *cli.StringFlag {
+	return &cli.StringFlag{
 		Name:     "version",
 		Usage:    "The new version to set",
 		Required: true,
-		Sources:  cli.EnvVars("BUMPER_GROUP_NEXT_VERSION"),
+		Sources:  envVars(env, "BUMPER_GROUP_NEXT_VERSION"),
 		Validator: func(s string) error {
 			_, err := semver.NewVersion(s)
 			return err
 		},
 	}
-)
+}
 
-var keyFlag = &cli.StringFlag{
-	Name:     "key",
-	Usage:    "Dot-separated path to the version field within the file (e.g. 'package.version')",
-	Required: true,
+func newKeyFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:     "key",
+		Usage:    "Dot-separated path to the version field within the file (e.g. 'package.version')",
+		Required: true,
+	}
 }
 
 func keyPath(c *cli.Command) string {
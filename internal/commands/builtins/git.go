@@ -0,0 +1,67 @@
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+func newTagFormatFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "tag-format",
+		Usage: "Tag name format with a {{version}} placeholder (defaults to the group's tag_format, or v{{version}})",
+		Validator: func(s string) error {
+			if !strings.Contains(s, "{{version}}") {
+				return fmt.Errorf("tag format %q has no {{version}} placeholder", s)
+			}
+			return nil
+		},
+	}
+}
+
+func newGitTagCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
+	return &cli.Command{
+		Name:  "current:git-tag",
+		Usage: "Get the current version of a release group from the highest matching git tag reachable from HEAD",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			newReleaseGroupFlag(env),
+			newTagFormatFlag(),
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			format := c.String("tag-format")
+			if format == "" {
+				format = res.Config.IndexReleaseGroups()[releaseGroup(c)].TagFormat
+			}
+			if format == "" {
+				format = workspace.DefaultTagFormat
+			}
+
+			latest, err := workspace.LatestTag(ctx, logger, res.Dir, format)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to read version from git tags", slog.String("dir", res.Dir), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+			if latest == nil {
+				logger.WarnContext(ctx, "no matching git tag found", slog.String("format", format))
+				fmt.Fprint(c.Root().Writer, "0.0.0")
+				return nil
+			}
+
+			fmt.Fprint(c.Root().Writer, latest.Version.String())
+
+			return nil
+		},
+	}
+}
@@ -8,21 +8,23 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"github.com/urfave/cli/v3"
 )
 
-func newJSONCurrentCommand(logger *slog.Logger) *cli.Command {
+func newJSONCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "current:json",
 		Usage: "Get the current version from a key in a JSON file",
 		Flags: []cli.Flag{
-			filePathFlag,
-			keyFlag,
+			newFilePathFlag(),
+			newKeyFlag(),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			path := filePath(c)
+			path := filePath(ctx, c)
 			key := keyPath(c)
 
 			data, err := os.ReadFile(path)
@@ -44,27 +46,34 @@ func newJSONCurrentCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			fmt.Print(sv.String())
+			fmt.Fprint(c.Root().Writer, sv.String())
 
 			return nil
 		},
 	}
 }
 
-func newJSONNextCommand(logger *slog.Logger) *cli.Command {
+func newJSONNextCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "next:json",
 		Usage: "Set the next version at a key in a JSON file",
 		Flags: []cli.Flag{
-			filePathsFlag,
-			keyFlag,
-			nextVersionFlag,
+			shared.NewLockTimeoutFlag(),
+			newFilePathsFlag(),
+			newKeyFlag(),
+			newNextVersionFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
+			lock, err := shared.LockWorkingDir(ctx, logger, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
 			key := keyPath(c)
 			version := nextVersion(c)
 
-			for _, path := range filePaths(c) {
+			for _, path := range filePaths(ctx, c) {
 				data, err := os.ReadFile(path)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to read json file", slog.String("file", path), slog.String("error", err.Error()))
@@ -83,7 +92,7 @@ func newJSONNextCommand(logger *slog.Logger) *cli.Command {
 					return cmd.Failed(err)
 				}
 
-				err = os.WriteFile(path, bs, 0644)
+				err = workspace.WriteFileAtomic(path, bs, 0644)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to write json file", slog.String("file", path), slog.String("error", err.Error()))
 					return cmd.Failed(err)
@@ -6,46 +6,55 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/tidwall/sjson"
 	"github.com/urfave/cli/v3"
 )
 
-var (
-	npmPackageFlag = &cli.StringFlag{
+func newNpmPackageFlag() *cli.StringFlag {
+	return &cli.StringFlag{
 		Name:      "package",
 		Usage:     "The path to a package.json file containing version information",
 		Required:  true,
 		TakesFile: true,
 	}
-	npmPackagesFlag = &cli.StringSliceFlag{
+}
+
+func newNpmPackagesFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
 		Name:      "package",
 		Usage:     "package.json paths to update (repeatable flag)",
 		Required:  true,
 		TakesFile: true,
 	}
-)
+}
 
-func npmPackagePath(c *cli.Command) string {
-	return c.String("package")
+func npmPackagePath(ctx context.Context, c *cli.Command) string {
+	return workspace.EnvironFrom(ctx).Path(c.String("package"))
 }
 
-func npmPackagePaths(c *cli.Command) []string {
-	return c.StringSlice("package")
+func npmPackagePaths(ctx context.Context, c *cli.Command) []string {
+	return environPaths(ctx, c.StringSlice("package"))
 }
 
-func newNPMCurrentCommand(logger *slog.Logger) *cli.Command {
+func newNPMCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "current:npm",
 		Usage: "Get the current version of a release group using an npm package.json file",
 		Flags: []cli.Flag{
-			npmPackageFlag,
+			newNpmPackageFlag(),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			packageFile := npmPackagePath(c)
+			packageFile := npmPackagePath(ctx, c)
 			data, err := os.ReadFile(packageFile)
 			if err != nil {
 				logger.ErrorContext(ctx, "failed to read package file", slog.String("file", packageFile), slog.String("error", err.Error()))
@@ -73,23 +82,42 @@ func newNPMCurrentCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			fmt.Print(sv.String())
+			fmt.Fprint(c.Root().Writer, sv.String())
 
 			return nil
 		},
 	}
 }
 
-func newNPMNextCommand(logger *slog.Logger) *cli.Command {
+func newNPMNextCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "next:npm",
 		Usage: "Set version of a release group in an npm package.json file",
 		Flags: []cli.Flag{
-			npmPackagesFlag,
-			nextVersionFlag,
+			shared.NewDirFlag(),
+			shared.NewLockTimeoutFlag(),
+			newNpmPackagesFlag(),
+			newNextVersionFlag(env),
+			&cli.StringFlag{
+				Name: "dependencies",
+				Usage: "Comma-separated group=version pairs of released release groups whose dependency pins to update," +
+					" as set by bumper commit. A group's package name is read from the package.json of its npm builtins",
+				Sources: envVars(env, "BUMPER_GROUP_DEPENDENCIES"),
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			for _, packageFile := range npmPackagePaths(c) {
+			lock, err := shared.LockWorkingDir(ctx, logger, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
+			pins, err := dependencyPins(ctx, logger, c)
+			if err != nil {
+				return err
+			}
+
+			for _, packageFile := range npmPackagePaths(ctx, c) {
 				data, err := os.ReadFile(packageFile)
 				switch {
 				case errors.Is(err, os.ErrNotExist):
@@ -106,7 +134,13 @@ func newNPMNextCommand(logger *slog.Logger) *cli.Command {
 					return cmd.Failed(err)
 				}
 
-				err = os.WriteFile(packageFile, bs, 0644)
+				bs, err = updateDependencyPins(bs, pins)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to update dependency pins in package file", slog.String("file", packageFile), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+
+				err = workspace.WriteFileAtomic(packageFile, bs, 0644)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to write package file", slog.String("file", packageFile), slog.String("error", err.Error()))
 					return cmd.Failed(err)
@@ -119,3 +153,131 @@ func newNPMNextCommand(logger *slog.Logger) *cli.Command {
 		},
 	}
 }
+
+// dependencyFields are the package.json fields whose pins next:npm updates.
+// Peer dependency ranges state compatibility rather than a pin, so they are
+// left alone.
+var dependencyFields = []string{"dependencies", "devDependencies", "optionalDependencies"}
+
+// dependencyPins maps the npm package names of the release groups in the
+// --dependencies flag to their new versions. A group's package name is the
+// name in the package.json used by its npm builtins, or the group's name
+// when it has none.
+func dependencyPins(ctx context.Context, logger *slog.Logger, c *cli.Command) (map[string]string, error) {
+	raw := c.String("dependencies")
+	if raw == "" {
+		return nil, nil
+	}
+
+	res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+	if err != nil {
+		return nil, err
+	}
+	groups := res.Config.IndexReleaseGroups()
+
+	pins := make(map[string]string)
+	for pair := range strings.SplitSeq(raw, ",") {
+		groupName, version, ok := strings.Cut(pair, "=")
+		if !ok || groupName == "" || version == "" {
+			err := fmt.Errorf("invalid dependency %q, want group=version", pair)
+			logger.ErrorContext(ctx, "invalid dependencies", slog.String("error", err.Error()))
+			return nil, cmd.Failed(err)
+		}
+
+		name := groupName
+		for _, packageFile := range groups[groupName].NpmPackages() {
+			if !filepath.IsAbs(packageFile) {
+				packageFile = filepath.Join(res.Dir, packageFile)
+			}
+			data, err := os.ReadFile(packageFile)
+			if err != nil {
+				continue
+			}
+			var pkg struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(data, &pkg) == nil && pkg.Name != "" {
+				name = pkg.Name
+				break
+			}
+		}
+		pins[name] = version
+	}
+
+	return pins, nil
+}
+
+// updateDependencyPins sets the pins of the packages in pins to their new
+// versions, keeping the range operator, as in "^1.2.3" or "workspace:~1.2.3".
+// Pins that are not a version, such as "workspace:*" or a git URL, are left
+// as they are.
+func updateDependencyPins(data []byte, pins map[string]string) ([]byte, error) {
+	if len(pins) == 0 {
+		return data, nil
+	}
+
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("parse package file: %w", err)
+	}
+
+	for _, field := range dependencyFields {
+		var deps map[string]string
+		if raw, ok := pkg[field]; !ok || json.Unmarshal(raw, &deps) != nil {
+			continue
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(pins)) {
+			spec, ok := deps[name]
+			if !ok {
+				continue
+			}
+			pinned, ok := repin(spec, pins[name])
+			if !ok {
+				continue
+			}
+
+			var err error
+			data, err = sjson.SetBytes(data, field+"."+sjsonEscape(name), pinned)
+			if err != nil {
+				return nil, fmt.Errorf("set %s pin of %s: %w", field, name, err)
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// repin replaces the version of a pin such as "^1.2.3", reporting false for
+// pins that are not a version with an optional range operator.
+func repin(spec string, version string) (string, bool) {
+	prefix, rest := "", spec
+	if after, ok := strings.CutPrefix(rest, "workspace:"); ok {
+		prefix, rest = "workspace:", after
+	}
+	for _, op := range []string{">=", "^", "~", "="} {
+		if after, ok := strings.CutPrefix(rest, op); ok {
+			prefix, rest = prefix+op, after
+			break
+		}
+	}
+
+	if _, err := semver.StrictNewVersion(rest); err != nil {
+		return "", false
+	}
+
+	return prefix + version, true
+}
+
+// sjsonEscape escapes the characters of key that sjson paths treat
+// specially.
+func sjsonEscape(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if strings.ContainsRune(`\.*?|#@!`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
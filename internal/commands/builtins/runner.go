@@ -0,0 +1,57 @@
+package builtins
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/o11y"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+// NewRunner returns the runner for the group commands of the workspace
+// resolved by res. Commands invoking the builtins run in-process, unless the
+// config sets subprocess_builtins.
+func NewRunner(logger *slog.Logger, res *shared.Resolution) workspace.ExecRunner {
+	if res.Config.SubprocessBuiltins {
+		return workspace.ExecRunner{}
+	}
+
+	return workspace.ExecRunner{Builtin: InProcess(logger, res)}
+}
+
+// InProcess returns a workspace.BuiltinFunc running the builtins as
+// `bumper builtins` would. Builtins run in the workspace resolved by res reuse
+// its config instead of loading it again.
+func InProcess(logger *slog.Logger, res *shared.Resolution) workspace.BuiltinFunc {
+	return func(ctx context.Context, env workspace.Environ, args []string, stdout io.Writer, stderr io.Writer) error {
+		if env.Dir == res.Dir {
+			env.Config = res.Config
+		}
+
+		// Log to stderr like a child process would, so the logs of groups
+		// released concurrently are buffered with the rest of their output.
+		logger := o11y.WithOutput(logger, stderr)
+
+		// The context of the running command would make it the parent of
+		// this one, and its writers this one's. Only cancellation carries
+		// over.
+		detached, cancel := context.WithCancel(workspace.WithEnviron(context.Background(), env))
+		defer cancel()
+		defer context.AfterFunc(ctx, cancel)()
+
+		root := &cli.Command{
+			Name:      "bumper",
+			Writer:    stdout,
+			ErrWriter: stderr,
+			Commands:  []*cli.Command{newCommand(logger, env)},
+			// Failures are returned to the runner and never exit the
+			// process.
+			ExitErrHandler: func(context.Context, *cli.Command, error) {},
+		}
+
+		return root.Run(detached, append([]string{"bumper", "builtins"}, args...))
+	}
+}
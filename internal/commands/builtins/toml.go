@@ -14,19 +14,21 @@ import (
 	"github.com/creachadair/tomledit"
 	"github.com/creachadair/tomledit/parser"
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
 )
 
-func newTOMLCurrentCommand(logger *slog.Logger) *cli.Command {
+func newTOMLCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "current:toml",
 		Usage: "Get the current version from a key in a TOML file",
 		Flags: []cli.Flag{
-			filePathFlag,
-			keyFlag,
+			newFilePathFlag(),
+			newKeyFlag(),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			path := filePath(c)
+			path := filePath(ctx, c)
 			key := keyPath(c)
 
 			segments, err := splitKeyPath(key)
@@ -73,7 +75,7 @@ func newTOMLCurrentCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			fmt.Print(sv.String())
+			fmt.Fprint(c.Root().Writer, sv.String())
 
 			return nil
 		},
@@ -95,16 +97,23 @@ func replaceTOMLValueInPlace(data []byte, entry *tomledit.Entry, newValue string
 	return []byte(strings.Join(lines, "")), true
 }
 
-func newTOMLNextCommand(logger *slog.Logger) *cli.Command {
+func newTOMLNextCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "next:toml",
 		Usage: "Set the next version at a key in a TOML file",
 		Flags: []cli.Flag{
-			filePathsFlag,
-			keyFlag,
-			nextVersionFlag,
+			shared.NewLockTimeoutFlag(),
+			newFilePathsFlag(),
+			newKeyFlag(),
+			newNextVersionFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
+			lock, err := shared.LockWorkingDir(ctx, logger, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
 			key := keyPath(c)
 			version := nextVersion(c)
 
@@ -120,7 +129,7 @@ func newTOMLNextCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			for _, path := range filePaths(c) {
+			for _, path := range filePaths(ctx, c) {
 				data, err := os.ReadFile(path)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to read toml file", slog.String("file", path), slog.String("error", err.Error()))
@@ -155,7 +164,7 @@ func newTOMLNextCommand(logger *slog.Logger) *cli.Command {
 					updated = out.Bytes()
 				}
 
-				err = os.WriteFile(path, updated, 0644)
+				err = workspace.WriteFileAtomic(path, updated, 0644)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to write toml file", slog.String("file", path), slog.String("error", err.Error()))
 					return cmd.Failed(err)
@@ -10,6 +10,8 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/goccy/go-yaml"
 	yamlparser "github.com/goccy/go-yaml/parser"
 	"github.com/urfave/cli/v3"
@@ -31,16 +33,16 @@ func yamlVersionPath(key string) (*yaml.Path, error) {
 	return builder.Build(), nil
 }
 
-func newYAMLCurrentCommand(logger *slog.Logger) *cli.Command {
+func newYAMLCurrentCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "current:yaml",
 		Usage: "Get the current version from a key in a YAML file",
 		Flags: []cli.Flag{
-			filePathFlag,
-			keyFlag,
+			newFilePathFlag(),
+			newKeyFlag(),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			path := filePath(c)
+			path := filePath(ctx, c)
 			key := keyPath(c)
 
 			versionPath, err := yamlVersionPath(key)
@@ -67,23 +69,30 @@ func newYAMLCurrentCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			fmt.Print(sv.String())
+			fmt.Fprint(c.Root().Writer, sv.String())
 
 			return nil
 		},
 	}
 }
 
-func newYAMLNextCommand(logger *slog.Logger) *cli.Command {
+func newYAMLNextCommand(logger *slog.Logger, env workspace.Environ) *cli.Command {
 	return &cli.Command{
 		Name:  "next:yaml",
 		Usage: "Set the next version at a key in a YAML file",
 		Flags: []cli.Flag{
-			filePathsFlag,
-			keyFlag,
-			nextVersionFlag,
+			shared.NewLockTimeoutFlag(),
+			newFilePathsFlag(),
+			newKeyFlag(),
+			newNextVersionFlag(env),
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
+			lock, err := shared.LockWorkingDir(ctx, logger, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
 			key := keyPath(c)
 			version := nextVersion(c)
 
@@ -93,7 +102,7 @@ func newYAMLNextCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			for _, path := range filePaths(c) {
+			for _, path := range filePaths(ctx, c) {
 				data, err := os.ReadFile(path)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to read yaml file", slog.String("file", path), slog.String("error", err.Error()))
@@ -131,7 +140,7 @@ func newYAMLNextCommand(logger *slog.Logger) *cli.Command {
 					out += "\n"
 				}
 
-				err = os.WriteFile(path, []byte(out), 0644)
+				err = workspace.WriteFileAtomic(path, []byte(out), 0644)
 				if err != nil {
 					logger.ErrorContext(ctx, "failed to write yaml file", slog.String("file", path), slog.String("error", err.Error()))
 					return cmd.Failed(err)
@@ -9,8 +9,10 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
+	"github.com/disintegrator/bumper/internal/changelog"
 	"github.com/disintegrator/bumper/internal/cmd"
 	"github.com/disintegrator/bumper/internal/commands/shared"
 	"github.com/disintegrator/bumper/internal/random"
@@ -20,9 +22,11 @@ import (
 )
 
 type bumpOptions struct {
-	groups  []string
-	level   string
-	message string
+	groups      []string
+	level       string
+	category    string
+	message     string
+	lockTimeout time.Duration
 }
 
 func NewCommand(logger *slog.Logger) *cli.Command {
@@ -31,10 +35,11 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 		Usage: "Bump the version for one or more release groups",
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
+			shared.NewLockTimeoutFlag(),
 			&cli.BoolFlag{
 				Name: "empty",
 				Usage: "Create an empty bump file without prompting for any input." +
-					" Useful for passing CI/CD checks that require bump files.",
+					" To require bump files in CI/CD, prefer `bumper check`, which knows which release groups a change touches.",
 			},
 			&cli.StringSliceFlag{
 				Name:  "group",
@@ -52,6 +57,11 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				Name:  "patch",
 				Usage: "Bump the patch version.",
 			},
+			&cli.StringFlag{
+				Name: "category",
+				Usage: "The Keep a Changelog category of the entry: added, changed, deprecated, removed, fixed or security." +
+					" Defaults to one following from the level.",
+			},
 			&cli.StringFlag{
 				Name:    "message",
 				Aliases: []string{"m"},
@@ -66,6 +76,12 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 			dir, cfg := res.Dir, res.Config
 
 			if c.Bool("empty") {
+				lock, err := shared.Lock(ctx, logger, dir, shared.LockTimeoutFlag(c))
+				if err != nil {
+					return err
+				}
+				defer shared.Unlock(ctx, logger, lock)
+
 				filename := workspace.BumpFilename(dir, random.GetRandomName())
 				logger.InfoContext(ctx, "Creating empty bump file", slog.String("file", filename))
 				f, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
@@ -96,6 +112,13 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				levelFlag = "patch"
 			}
 
+			categoryFlag := c.String("category")
+			if categoryFlag != "" && !changelog.IsCategory(categoryFlag) {
+				err := fmt.Errorf("unknown changelog category %q, want one of %s", categoryFlag, strings.Join(changelog.CategoryNames, ", "))
+				logger.ErrorContext(ctx, "invalid flags", slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
 			indexed := cfg.IndexReleaseGroups()
 			if len(groupsFlag) > 0 {
 				for _, g := range groupsFlag {
@@ -113,9 +136,11 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 			groupsDeduped := slices.Sorted(maps.Keys(deduped))
 
 			bumpOpts := &bumpOptions{
-				groups:  groupsDeduped,
-				level:   levelFlag,
-				message: messageFlag,
+				groups:      groupsDeduped,
+				level:       levelFlag,
+				category:    categoryFlag,
+				message:     messageFlag,
+				lockTimeout: shared.LockTimeoutFlag(c),
 			}
 
 			groupOpts := make([]huh.Option[string], 0, len(cfg.Groups))
@@ -196,10 +221,21 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 	}
 }
 
+// writeBumpFile writes the bump file holding the workspace lock, which is
+// only taken once the prompts are answered.
 func writeBumpFile(ctx context.Context, logger *slog.Logger, dir string, bumpOpts *bumpOptions) error {
-	bumps := make(map[string]string)
+	lock, err := shared.Lock(ctx, logger, dir, bumpOpts.lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer shared.Unlock(ctx, logger, lock)
+
+	bumps := make(map[string]any)
 	for _, groupName := range bumpOpts.groups {
 		bumps[groupName] = bumpOpts.level
+		if bumpOpts.category != "" {
+			bumps[groupName] = map[string]string{"level": bumpOpts.level, "category": bumpOpts.category}
+		}
 	}
 	ymlbs, err := yaml.Marshal(bumps)
 	if err != nil {
@@ -1,12 +1,13 @@
 package cat
 
 import (
+	"bytes"
 	"context"
 	"log/slog"
-	"os"
 	"strings"
 
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
 	"github.com/disintegrator/bumper/internal/commands/shared"
 	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
@@ -47,12 +48,28 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
-			runner := workspace.ExecRunner{}
-			if err := runner.Run(ctx, res.Dir, inv, os.Stdout); err != nil {
+			// Under --output json the notes are captured for the document
+			// instead of streamed.
+			out := c.Root().Writer
+			jsonOutput := shared.OutputFlag(c) == shared.OutputJSON
+			notes := new(bytes.Buffer)
+			stdout := out
+			if jsonOutput {
+				stdout = notes
+			}
+
+			runner := builtins.NewRunner(logger, res)
+			if err := runner.Run(ctx, res.Dir, inv, stdout); err != nil {
 				logger.ErrorContext(ctx, "failed to execute cat command", slog.String("error", err.Error()), slog.String("command", strings.Join(group.CatCMD, " ")))
 				return cmd.Failed(err)
 			}
 
+			if jsonOutput {
+				return shared.WriteDocument(out, logger, &shared.Document{Groups: []shared.GroupDocument{
+					{Name: group.Name, Version: c.String("version"), Notes: notes.String()},
+				}})
+			}
+
 			return nil
 		},
 	}
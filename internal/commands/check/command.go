@@ -0,0 +1,147 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+// maxListedFiles caps the changed files listed per group in the report.
+const maxListedFiles = 5
+
+func NewCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "check",
+		Usage: "Fail if release groups changed since the base revision have no bump file added on the branch",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			&cli.StringFlag{
+				Name:     "base",
+				Usage:    "The git revision the branch is compared against (e.g. origin/main)",
+				Required: true,
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			base := c.String("base")
+			changed, err := workspace.ChangedFiles(ctx, res.Dir, base)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to diff against base revision", slog.String("base", base), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			return run(ctx, logger, res.Dir, res.Config, changed, c.Root().Writer, shared.OutputFlag(c))
+		},
+	}
+}
+
+// run checks that every release group owning one of the changed files has a
+// bump file among the changed files, which may set its level to none. An empty
+// bump file acknowledges every group. changed holds slash-separated paths
+// relative to dir; bump files are read from the worktree. Under --output json
+// the groups missing a bump file are printed as a shared.Document.
+func run(ctx context.Context, logger *slog.Logger, dir string, cfg *workspace.Config, changed []string, stdout io.Writer, output string) error {
+	doc := &shared.Document{}
+	report := func() error {
+		if output != shared.OutputJSON {
+			return nil
+		}
+		return shared.WriteDocument(stdout, logger, doc)
+	}
+
+	touched := workspace.TouchedGroups(cfg, changed)
+	if len(touched) == 0 {
+		logger.InfoContext(ctx, "no release group owns a changed file")
+		return report()
+	}
+
+	bumped := make(map[string]struct{})
+	var empty string
+	for _, file := range changed {
+		if ok, _ := path.Match(".bumper/bump-*.md", file); !ok {
+			continue
+		}
+
+		bump, err := workspace.ReadBumpFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			// Deleted on the branch, e.g. by a release.
+			continue
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read bump file", slog.String("file", file), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+
+		if len(bump.Levels) == 0 {
+			// An empty bump file, e.g. from `bumper bump --empty`, states
+			// that the branch needs no release.
+			empty = file
+		}
+		// Any level counts, including none for changes that need no release
+		// of the group.
+		for group := range bump.Levels {
+			bumped[group] = struct{}{}
+		}
+	}
+
+	if empty != "" {
+		logger.InfoContext(ctx, "empty bump file acknowledges every changed release group", slog.String("file", empty))
+		return report()
+	}
+
+	var missing []string
+	for _, group := range cfg.Groups {
+		files, ok := touched[group.Name]
+		if !ok {
+			continue
+		}
+		if _, ok := bumped[group.Name]; ok {
+			continue
+		}
+
+		missing = append(missing, group.Name)
+		doc.Groups = append(doc.Groups, shared.GroupDocument{Name: group.Name, Files: files})
+	}
+
+	if len(missing) == 0 {
+		logger.InfoContext(ctx, "every changed release group has a bump file", slog.Int("groups", len(touched)))
+		return report()
+	}
+
+	err := fmt.Errorf("release groups changed without a bump file: %s", strings.Join(missing, ", "))
+	logger.ErrorContext(ctx, err.Error())
+	if output == shared.OutputJSON {
+		if werr := report(); werr != nil {
+			return werr
+		}
+		return cmd.Failed(err)
+	}
+
+	fmt.Fprintln(stdout, "release groups changed without a bump file:")
+	for _, group := range doc.Groups {
+		fmt.Fprintf(stdout, "  %s\n", group.Name)
+		for _, file := range group.Files[:min(len(group.Files), maxListedFiles)] {
+			fmt.Fprintf(stdout, "    %s\n", file)
+		}
+		if len(group.Files) > maxListedFiles {
+			fmt.Fprintf(stdout, "    ... and %d more\n", len(group.Files)-maxListedFiles)
+		}
+	}
+	fmt.Fprintf(stdout, "add one with `bumper bump --group %s`\n", strings.Join(missing, " --group "))
+
+	return cmd.Failed(err)
+}
@@ -0,0 +1,138 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+func testGroup(name string, paths ...string) workspace.ReleaseGroup {
+	return workspace.ReleaseGroup{
+		Name:         name,
+		ChangelogCMD: []string{"true"},
+		CatCMD:       []string{"true"},
+		CurrentCMD:   []string{"true"},
+		NextCMD:      []string{"true"},
+		Paths:        paths,
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(workspace.Dir(dir), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	if err := os.WriteFile(workspace.BumpFilename(dir, "sdk"), []byte("---\nsdk: patch\n---\n\nfix\n"), 0o644); err != nil {
+		t.Fatalf("write bump file: %v", err)
+	}
+	if err := os.WriteFile(workspace.BumpFilename(dir, "empty"), []byte("---\n---\n"), 0o644); err != nil {
+		t.Fatalf("write bump file: %v", err)
+	}
+	if err := os.WriteFile(workspace.BumpFilename(dir, "web-none"), []byte("---\nweb: none\n---\n"), 0o644); err != nil {
+		t.Fatalf("write bump file: %v", err)
+	}
+
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroup("sdk", "packages/sdk/**"),
+		testGroup("web", "packages/web/**"),
+		testGroup("docs", "docs/**"),
+	}}
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name    string
+		changed []string
+		wantErr bool
+		wantOut string
+	}{
+		{
+			name:    "bumped group",
+			changed: []string{".bumper/bump-sdk.md", "packages/sdk/client.go"},
+		},
+		{
+			name:    "untouched groups",
+			changed: []string{"README.md"},
+		},
+		{
+			name:    "none level",
+			changed: []string{".bumper/bump-web-none.md", "packages/web/app.go"},
+		},
+		{
+			name:    "empty bump file",
+			changed: []string{".bumper/bump-empty.md", "packages/sdk/client.go", "packages/web/app.go"},
+		},
+		{
+			name:    "missing bump",
+			changed: []string{".bumper/bump-sdk.md", "packages/sdk/client.go", "packages/web/app.go"},
+			wantErr: true,
+			wantOut: "release groups changed without a bump file:\n  web\n    packages/web/app.go\nadd one with `bumper bump --group web`\n",
+		},
+		{
+			name:    "deleted bump file",
+			changed: []string{".bumper/bump-gone.md", "docs/index.md"},
+			wantErr: true,
+			wantOut: "release groups changed without a bump file:\n  docs\n    docs/index.md\nadd one with `bumper bump --group docs`\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(t.Context(), logger, dir, cfg, tt.changed, &stdout, shared.OutputText)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRunTruncatesFileList(t *testing.T) {
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("sdk", "packages/sdk/**")}}
+	changed := []string{
+		"packages/sdk/a.go", "packages/sdk/b.go", "packages/sdk/c.go",
+		"packages/sdk/d.go", "packages/sdk/e.go", "packages/sdk/f.go", "packages/sdk/g.go",
+	}
+
+	var stdout bytes.Buffer
+	if err := run(t.Context(), slog.New(slog.DiscardHandler), t.TempDir(), cfg, changed, &stdout, shared.OutputText); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(stdout.String(), "    ... and 2 more\n") {
+		t.Errorf("stdout = %q, want the file list truncated", stdout.String())
+	}
+}
+
+func TestRunJSONOutput(t *testing.T) {
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroup("sdk", "packages/sdk/**"),
+		testGroup("web", "packages/web/**"),
+	}}
+	changed := []string{"packages/sdk/a.go", "packages/sdk/b.go", "packages/web/app.go"}
+
+	var stdout bytes.Buffer
+	if err := run(t.Context(), slog.New(slog.DiscardHandler), t.TempDir(), cfg, changed, &stdout, shared.OutputJSON); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var doc shared.Document
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		t.Fatalf("stdout = %q, want a JSON document: %v", stdout.String(), err)
+	}
+	want := []shared.GroupDocument{
+		{Name: "sdk", Files: []string{"packages/sdk/a.go", "packages/sdk/b.go"}},
+		{Name: "web", Files: []string{"packages/web/app.go"}},
+	}
+	if !reflect.DeepEqual(doc.Groups, want) {
+		t.Errorf("groups = %+v, want %+v", doc.Groups, want)
+	}
+}
@@ -0,0 +1,118 @@
+package commit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+var errNoCheckpoint = errors.New("no interrupted commit found")
+
+// stepDescriptions describe a group's state once a step has completed.
+var stepDescriptions = map[workspace.CommitStep]string{
+	workspace.CommitStepVersion:   "version computed, next_cmd not run yet",
+	workspace.CommitStepNext:      "version applied, changelog not amended yet",
+	workspace.CommitStepChangelog: "version applied, changelog amended",
+}
+
+// writeCheckpointReport describes an interrupted commit: the groups it
+// released and how far it got with the groups it was releasing. stale marks
+// a checkpoint whose batch of bump files has changed since.
+func writeCheckpointReport(w io.Writer, dir string, checkpoint *workspace.CommitCheckpoint, stale bool) {
+	filename := workspace.CommitCheckpointFilename(dir)
+	if rel, err := filepath.Rel(dir, filename); err == nil {
+		filename = rel
+	}
+
+	fmt.Fprintf(w, "Interrupted commit of %d bump files (%s):\n", len(checkpoint.Batch), filename)
+	for _, groupName := range slices.Sorted(maps.Keys(checkpoint.Released)) {
+		fmt.Fprintf(w, "  %s: released %s\n", groupName, checkpoint.Released[groupName])
+	}
+	for _, groupName := range slices.Sorted(maps.Keys(checkpoint.Progress)) {
+		progress := checkpoint.Progress[groupName]
+		fmt.Fprintf(w, "  %s: releasing %s, %s\n", groupName, progress.Version, stepDescriptions[progress.Step])
+	}
+	if len(checkpoint.Released) == 0 && len(checkpoint.Progress) == 0 {
+		fmt.Fprintln(w, "  no group released yet")
+	}
+
+	if stale {
+		fmt.Fprintln(w, "The pending bump files changed since; the next commit releases them from scratch.")
+	}
+}
+
+// resume reports the interrupted commit that a --resume run continues. It
+// fails when there is none, or when the pending bumps changed since, since
+// the run would then release the batch from scratch instead.
+func resume(ctx context.Context, logger *slog.Logger, dir string, stdout io.Writer) error {
+	checkpoint, stale, err := loadCheckpoint(dir)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+	if checkpoint == nil {
+		logger.ErrorContext(ctx, "nothing to resume", slog.String("dir", dir))
+		return cmd.Failed(errNoCheckpoint)
+	}
+
+	writeCheckpointReport(stdout, dir, checkpoint, stale)
+	if stale {
+		err := errors.New("pending bumps changed since the interrupted commit")
+		logger.ErrorContext(ctx, "cannot resume; run `bumper commit --abort` to discard the checkpoint", slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	return nil
+}
+
+// abort reports and removes the checkpoint of an interrupted commit. Files
+// changed by the groups it released are left as they are.
+func abort(ctx context.Context, logger *slog.Logger, dir string, stdout io.Writer) error {
+	checkpoint, stale, err := loadCheckpoint(dir)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+	if checkpoint == nil {
+		fmt.Fprintln(stdout, "No interrupted commit to abort.")
+		return nil
+	}
+
+	writeCheckpointReport(stdout, dir, checkpoint, stale)
+
+	if err := workspace.DeleteCommitCheckpoint(dir); err != nil {
+		logger.ErrorContext(ctx, "failed to delete commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	fmt.Fprintln(stdout, "Aborted: the checkpoint was removed and the next commit releases every pending bump from scratch.")
+	if len(checkpoint.Released) > 0 || len(checkpoint.Progress) > 0 {
+		fmt.Fprintln(stdout, "Files changed by the groups above were left as they are; revert them before committing again.")
+	}
+
+	return nil
+}
+
+// loadCheckpoint reads the checkpoint of an interrupted commit and reports
+// whether it is stale: recorded for a different batch of bump files.
+func loadCheckpoint(dir string) (*workspace.CommitCheckpoint, bool, error) {
+	checkpoint, err := workspace.LoadCommitCheckpoint(dir)
+	if err != nil || checkpoint == nil {
+		return nil, false, err
+	}
+
+	fingerprint, err := workspace.BumpBatchFingerprint(dir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return checkpoint, !maps.Equal(checkpoint.Batch, fingerprint), nil
+}
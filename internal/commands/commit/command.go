@@ -2,18 +2,19 @@ package commit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"maps"
 	"os"
-	"slices"
+	"path/filepath"
 	"strings"
 
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
 	"github.com/disintegrator/bumper/internal/commands/shared"
 	"github.com/disintegrator/bumper/internal/workspace"
-	"github.com/samber/lo"
 	"github.com/urfave/cli/v3"
 )
 
@@ -23,6 +24,47 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 		Usage: "Commit pending version bumps",
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
+			shared.NewLockTimeoutFlag(),
+			&cli.BoolFlag{
+				Name:  "abort",
+				Usage: "Report and discard the checkpoint of an interrupted commit, so the next commit releases every pending bump from scratch",
+			},
+			&cli.BoolFlag{
+				Name: "atomic",
+				Usage: "Snapshot the git worktree before running group commands and restore it if the release fails," +
+					" so a failed commit leaves the repository as it started",
+			},
+			&cli.BoolFlag{
+				Name: "dry-run",
+				Usage: "Print the versions that would be released, the group commands that would run and" +
+					" the bump files that would be deleted, without changing anything",
+			},
+			&cli.BoolFlag{
+				Name: "git-commit",
+				Usage: "Stage the files changed by the release and create a git commit listing the released groups." +
+					" Fails if the worktree has uncommitted changes beforehand",
+			},
+			&cli.IntFlag{
+				Name: "jobs",
+				Usage: "Release up to this many groups at once. Groups wait for the groups they depend on" +
+					" and for running groups that write the same files, e.g. a shared changelog",
+				Value: 1,
+			},
+			&cli.StringFlag{
+				Name: "manifest",
+				Usage: "Write a JSON release manifest to this path, recording each released group's previous and new" +
+					" version, bump level, changelog section and contributing commits",
+				TakesFile: true,
+			},
+			&cli.BoolFlag{
+				Name: "resume",
+				Usage: "Report the progress of an interrupted commit and continue it." +
+					" Fails if there is none or the pending bumps changed since",
+			},
+			&cli.BoolFlag{
+				Name:  "tag",
+				Usage: "Create an annotated git tag on the release commit for every released group that has a tag_format. Requires --git-commit",
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
@@ -30,14 +72,88 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				return err
 			}
 
-			return run(ctx, logger, workspace.ExecRunner{}, workspace.NewGitProvenance(logger, res.Dir), res.Dir, res.Config, os.Stdout)
+			// The lock is shared with the group commands, so builtins run
+			// by the release do not wait for it.
+			lock, err := shared.Lock(ctx, logger, res.Dir, shared.LockTimeoutFlag(c))
+			if err != nil {
+				return err
+			}
+			defer shared.Unlock(ctx, logger, lock)
+
+			if c.Bool("abort") {
+				if c.Bool("resume") {
+					err := errors.New("--abort and --resume are mutually exclusive")
+					logger.ErrorContext(ctx, "invalid flags", slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+				return abort(ctx, logger, res.Dir, c.Root().Writer)
+			}
+
+			if c.Int("jobs") < 1 {
+				err := fmt.Errorf("--jobs must be at least 1, got %d", c.Int("jobs"))
+				logger.ErrorContext(ctx, "invalid flags", slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+			// Tags are created on HEAD, which is only the release commit with
+			// --git-commit.
+			if c.Bool("tag") && !c.Bool("git-commit") {
+				err := errors.New("--tag requires --git-commit")
+				logger.ErrorContext(ctx, "invalid flags", slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			opts := options{
+				atomic:    c.Bool("atomic"),
+				dryRun:    c.Bool("dry-run"),
+				output:    shared.OutputFlag(c),
+				gitCommit: c.Bool("git-commit"),
+				jobs:      c.Int("jobs"),
+				manifest:  c.String("manifest"),
+				resume:    c.Bool("resume"),
+				tag:       c.Bool("tag"),
+			}
+
+			runner := builtins.NewRunner(logger, res)
+			runner.Env = []string{lock.Env()}
+
+			// The stderr of group commands, including the buffered stderr
+			// of groups released concurrently, goes to the error writer.
+			ctx = workspace.WithStderr(ctx, c.Root().ErrWriter)
+
+			return run(ctx, logger, runner, workspace.NewGitProvenance(logger, res.Dir), res.Dir, res.Config, c.Root().Writer, opts)
 		},
 	}
 }
 
+// options are the commit command's behaviour switches.
+type options struct {
+	// atomic restores the worktree to its state before the release when the
+	// release fails.
+	atomic bool
+	// dryRun previews the release: group commands other than current_cmd
+	// are recorded and printed instead of run, and neither bump files, the
+	// checkpoint nor the prerelease state are written.
+	dryRun bool
+	// output is the --output format.
+	output string
+	// gitCommit commits the files changed by the release once it completes.
+	gitCommit bool
+	// jobs is how many groups may be released at once.
+	jobs int
+	// manifest is the path of the release manifest to write, if any.
+	manifest string
+	// resume requires an interrupted commit of the pending bumps to
+	// continue, and reports its progress first.
+	resume bool
+	// tag creates a git tag for every released group with a tag format once
+	// the release completes.
+	tag bool
+}
+
 // run commits all pending bumps. Bump files are the release intent: they are
 // deleted only once every release group's version and changelog commands have
 // succeeded, so a failure partway through leaves the workspace recoverable.
+// With opts.atomic, such a failure also undoes the groups already released.
 func run(
 	ctx context.Context,
 	logger *slog.Logger,
@@ -46,18 +162,98 @@ func run(
 	dir string,
 	cfg *workspace.Config,
 	stdout io.Writer,
-) error {
+	opts options,
+) (err error) {
 	cfgGroups := cfg.IndexReleaseGroups()
 
+	// Under --output json, stdout carries only the final document: previews
+	// are dropped and the output of group commands goes to stderr.
+	preview, commandOut := stdout, io.Writer(os.Stdout)
+	if opts.output == shared.OutputJSON {
+		preview, commandOut = io.Discard, os.Stderr
+	}
+
+	// In a dry run, each group records its commands with a RecordingRunner of
+	// its own, so its preview stays with the rest of its output.
+	groupRunner := runner
+	if opts.dryRun {
+		runner = &workspace.RecordingRunner{Runner: runner, Out: preview}
+	}
+
+	if opts.resume {
+		if err := resume(ctx, logger, dir, preview); err != nil {
+			return err
+		}
+	}
+
+	// The release commit must contain only what this run changed, so the
+	// worktree has to start out clean.
+	var changedBefore []string
+	if opts.gitCommit {
+		changedBefore, err = workspace.WorktreeChanges(dir)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read git worktree status", slog.String("dir", dir), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+		if len(changedBefore) > 0 {
+			err := fmt.Errorf("worktree has uncommitted changes: %s", strings.Join(changedBefore, ", "))
+			logger.ErrorContext(ctx, "commit or stash uncommitted changes before using --git-commit", slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+	}
+
+	// The snapshot is the rollback point of an atomic release. It is
+	// dropped once the release is complete: later failures, e.g. tagging,
+	// do not undo it.
+	var snapshot *workspace.WorktreeSnapshot
+	if opts.atomic && !opts.dryRun {
+		snapshot, err = workspace.SnapshotWorktree(dir)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to snapshot git worktree for an atomic commit", slog.String("dir", dir), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+		defer func() {
+			if err == nil || snapshot == nil {
+				return
+			}
+			if restoreErr := snapshot.Restore(); restoreErr != nil {
+				logger.ErrorContext(ctx, "failed to restore git worktree after the failed release", slog.String("dir", dir), slog.String("error", restoreErr.Error()))
+				return
+			}
+			logger.WarnContext(ctx, "release failed; restored the git worktree to its state before the commit", slog.String("dir", dir))
+		}()
+	}
+
 	statuses, err := workspace.CollectBumps(ctx, logger, dir, cfg, provenance)
 	if err != nil {
 		logger.ErrorContext(ctx, "failed to collect pending bumps", slog.String("dir", dir), slog.String("error", err.Error()))
 		return cmd.Failed(err)
 	}
 
+	pre, err := workspace.LoadPreState(dir)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load prerelease state", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	// Graduating groups release even without pending bumps of their own:
+	// their release carries every entry accumulated on the channel.
+	for groupName, p := range pre.Groups {
+		if p.Mode == workspace.PreModeExit {
+			statuses[groupName] = p.GraduationStatus(statuses[groupName])
+		}
+	}
+
 	if len(statuses) == 0 {
 		logger.InfoContext(ctx, "no pending version bumps found", slog.String("dir", dir))
 
+		if opts.dryRun {
+			if err := printPendingDeletions(preview, dir); err != nil {
+				return err
+			}
+			return writeDocument(stdout, logger, opts, nil, nil, nil, nil)
+		}
+
 		// Nothing to release, so bump files present here carry no release
 		// intent (empty or unknown-group ones) and are safe to clean up,
 		// along with any checkpoint from an abandoned batch.
@@ -69,8 +265,9 @@ func run(
 			logger.ErrorContext(ctx, "failed to delete commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
 			return cmd.Failed(err)
 		}
+		snapshot = nil
 
-		return nil
+		return writeDocument(stdout, logger, opts, nil, nil, nil, nil)
 	}
 
 	checkpoint, err := loadCheckpointForBatch(ctx, logger, dir)
@@ -78,58 +275,76 @@ func run(
 		return cmd.Failed(err)
 	}
 
-	entries := lo.Entries(statuses)
-	slices.SortStableFunc(entries, func(e1, e2 lo.Entry[string, *workspace.ReleaseGroupStatus]) int {
-		return strings.Compare(e1.Key, e2.Key)
-	})
-
-	committedGroups := make([]string, 0, len(statuses))
-	for _, entry := range entries {
-		groupName, status := entry.Key, entry.Value
-
-		g, ok := cfgGroups[groupName]
-		if !ok {
+	for groupName := range statuses {
+		if _, ok := cfgGroups[groupName]; !ok {
 			logger.WarnContext(ctx, "skipping commit for unknown group", slog.String("group", groupName))
-			continue
 		}
+	}
 
-		if status.Level == 0 {
-			continue
-		}
+	// Dependencies release first so their versions are known when the
+	// groups depending on them record the update.
+	order, err := cfg.ReleaseOrder()
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to order release groups", slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
 
-		if version, released := checkpoint.Released[groupName]; released {
-			logger.InfoContext(ctx, "skipping group released by a previous attempt at this batch", slog.String("group", groupName), slog.String("version", version))
-			committedGroups = append(committedGroups, groupName)
-			continue
-		}
+	// Fixed sets share one version, computed up front from the members'
+	// current versions before any of them moves.
+	fixedVersions, err := workspace.FixedVersions(ctx, runner, dir, cfg, statuses, pre, checkpoint.Targets())
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to compute fixed set versions", slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
 
-		nextVersion, err := workspace.GetNextVersion(ctx, runner, dir, g, status.Level)
-		if err != nil {
-			logger.ErrorContext(ctx, "failed to get next version", slog.String("group", groupName), slog.String("error", err.Error()))
-			return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+	queue := make([]string, 0, len(statuses))
+	for _, groupName := range order {
+		if status, ok := statuses[groupName]; ok && status.Level != workspace.BumpLevelNone {
+			queue = append(queue, groupName)
 		}
+	}
 
-		err = commitVersionBump(ctx, runner, dir, g, nextVersion)
-		if err != nil {
-			logger.ErrorContext(ctx, "failed to commit version bump", slog.String("group", groupName), slog.String("version", nextVersion), slog.String("error", err.Error()))
-			return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
-		}
+	r := &releaser{
+		logger:        logger,
+		runner:        groupRunner,
+		dir:           dir,
+		cfgGroups:     cfgGroups,
+		statuses:      statuses,
+		pre:           pre,
+		checkpoint:    checkpoint,
+		fixedVersions: fixedVersions,
+		opts:          opts,
+		preview:       preview,
+		commandOut:    commandOut,
+	}
+	if err := r.releaseAll(ctx, queue, opts.jobs); err != nil {
+		return err
+	}
+	committedGroups := queue
 
-		err = commitChangelog(ctx, runner, dir, g, nextVersion, status)
-		if err != nil {
-			logger.ErrorContext(ctx, "failed to commit changelog", slog.String("group", groupName), slog.String("version", nextVersion), slog.String("error", err.Error()))
-			return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+	if opts.dryRun {
+		if opts.gitCommit {
+			message, err := workspace.RenderCommitMessage(cfg.CommitMessage, releases(cfgGroups, committedGroups, checkpoint.Released))
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to render commit message", slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+			fmt.Fprintf(preview, "would create git commit:\n%s\n", indent(message))
 		}
-
-		// Record the group as released before moving on, so a failure in a
-		// later group never re-releases this one on retry.
-		checkpoint.Released[groupName] = nextVersion
-		if err := workspace.SaveCommitCheckpoint(dir, checkpoint); err != nil {
-			logger.ErrorContext(ctx, "failed to save commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
-			return cmd.Failed(err)
+		if opts.manifest != "" {
+			fmt.Fprintf(preview, "would write manifest %s\n", opts.manifest)
 		}
-
-		committedGroups = append(committedGroups, groupName)
+		if opts.tag {
+			for _, groupName := range committedGroups {
+				if name := cfgGroups[groupName].TagName(checkpoint.Released[groupName]); name != "" {
+					fmt.Fprintf(preview, "would tag %s\n", name)
+				}
+			}
+		}
+		if err := printPendingDeletions(preview, dir); err != nil {
+			return err
+		}
+		return writeDocument(stdout, logger, opts, committedGroups, checkpoint.Released, statuses, nil)
 	}
 
 	// Every group's commands succeeded; the release intent is consumed.
@@ -141,12 +356,123 @@ func run(
 		logger.ErrorContext(ctx, "failed to delete commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
 		return cmd.Failed(err)
 	}
+	snapshot = nil
+
+	if opts.gitCommit {
+		if err := commitRelease(ctx, logger, dir, cfg, releases(cfgGroups, committedGroups, checkpoint.Released), changedBefore); err != nil {
+			return err
+		}
+	}
+
+	// tags maps the released groups to the tags created for them.
+	tags := map[string]string{}
+	if opts.tag {
+		for _, groupName := range committedGroups {
+			g := cfgGroups[groupName]
+			if g.TagFormat == "" {
+				continue
+			}
+
+			name, err := workspace.TagRelease(ctx, runner, dir, g, checkpoint.Released[groupName])
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to tag release; the release itself is complete, retry tagging with `bumper tag`", slog.String("group", groupName), slog.String("error", err.Error()))
+				return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+			}
+			logger.InfoContext(ctx, "created tag", slog.String("group", groupName), slog.String("tag", name))
+			tags[groupName] = name
+		}
+	}
+
+	if opts.manifest != "" {
+		manifest, err := buildManifest(ctx, runner, dir, cfgGroups, committedGroups, checkpoint, statuses)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to build release manifest; the release itself is complete", slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+		if err := workspace.WriteReleaseManifest(opts.manifest, manifest); err != nil {
+			logger.ErrorContext(ctx, "failed to write release manifest; the release itself is complete", slog.String("file", opts.manifest), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+	}
+
+	if opts.output == shared.OutputJSON {
+		return writeDocument(stdout, logger, opts, committedGroups, checkpoint.Released, statuses, tags)
+	}
 
 	fmt.Fprintln(stdout, strings.Join(committedGroups, "\n"))
+	// The created tags follow, as bumper tag prints them.
+	for _, groupName := range committedGroups {
+		if name, ok := tags[groupName]; ok {
+			fmt.Fprintln(stdout, name)
+		}
+	}
 
 	return nil
 }
 
+// buildManifest records the released groups in release order, reading each
+// group's new changelog section through its cat command.
+func buildManifest(
+	ctx context.Context,
+	runner workspace.Runner,
+	dir string,
+	cfgGroups map[string]workspace.ReleaseGroup,
+	committedGroups []string,
+	checkpoint *workspace.CommitCheckpoint,
+	statuses map[string]*workspace.ReleaseGroupStatus,
+) (*workspace.ReleaseManifest, error) {
+	manifest := &workspace.ReleaseManifest{Releases: make([]workspace.ReleaseRecord, 0, len(committedGroups))}
+	for _, groupName := range committedGroups {
+		g := cfgGroups[groupName]
+		version := checkpoint.Released[groupName]
+
+		notes, err := workspace.GetReleaseNotes(ctx, runner, dir, g, version)
+		if err != nil {
+			return nil, fmt.Errorf("release group %s: %w", groupName, err)
+		}
+
+		manifest.Releases = append(manifest.Releases, workspace.ReleaseRecord{
+			Group:           groupName,
+			DisplayName:     g.DisplayName,
+			PreviousVersion: checkpoint.Previous[groupName],
+			Version:         version,
+			Level:           statuses[groupName].Level,
+			Changelog:       notes,
+			Commits:         statuses[groupName].CommitSHAs(),
+		})
+	}
+
+	return manifest, nil
+}
+
+// writeDocument prints the --output json document listing the released
+// groups and the tags created for them. It prints nothing in text mode.
+func writeDocument(
+	stdout io.Writer,
+	logger *slog.Logger,
+	opts options,
+	committedGroups []string,
+	versions map[string]string,
+	statuses map[string]*workspace.ReleaseGroupStatus,
+	tags map[string]string,
+) error {
+	if opts.output != shared.OutputJSON {
+		return nil
+	}
+
+	doc := &shared.Document{DryRun: opts.dryRun, Groups: make([]shared.GroupDocument, 0, len(committedGroups))}
+	for _, groupName := range committedGroups {
+		doc.Groups = append(doc.Groups, shared.GroupDocument{
+			Name:    groupName,
+			Level:   statuses[groupName].Level,
+			Version: versions[groupName],
+			Tag:     tags[groupName],
+		})
+	}
+
+	return shared.WriteDocument(stdout, logger, doc)
+}
+
 // loadCheckpointForBatch returns the checkpoint from a previously failed
 // attempt at the current batch of pending bumps, or a fresh one. A checkpoint
 // recorded for a different batch (the bump files changed since the failure)
@@ -179,26 +505,101 @@ func loadCheckpointForBatch(ctx context.Context, logger *slog.Logger, dir string
 	return checkpoint, nil
 }
 
-func commitVersionBump(ctx context.Context, runner workspace.Runner, dir string, group workspace.ReleaseGroup, versionStr string) error {
-	inv, err := workspace.NewNextInvocation(group, versionStr)
+// releases lists the released groups and their versions in release order.
+func releases(cfgGroups map[string]workspace.ReleaseGroup, committedGroups []string, versions map[string]string) []workspace.Release {
+	result := make([]workspace.Release, 0, len(committedGroups))
+	for _, groupName := range committedGroups {
+		result = append(result, workspace.Release{
+			Group:       groupName,
+			DisplayName: cfgGroups[groupName].DisplayName,
+			Version:     versions[groupName],
+		})
+	}
+
+	return result
+}
+
+// commitRelease stages the files the release changed, found by comparing the
+// worktree against the changedBefore snapshot, and commits them.
+func commitRelease(ctx context.Context, logger *slog.Logger, dir string, cfg *workspace.Config, released []workspace.Release, changedBefore []string) error {
+	message, err := workspace.RenderCommitMessage(cfg.CommitMessage, released)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to render commit message", slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	changedAfter, err := workspace.WorktreeChanges(dir)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to read git worktree status", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	touched := workspace.TouchedFiles(changedBefore, changedAfter)
+	if len(touched) == 0 {
+		logger.WarnContext(ctx, "release changed no files; skipping git commit", slog.String("dir", dir))
+		return nil
+	}
+
+	hash, err := workspace.CommitFiles(dir, touched, message)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create release commit; the release itself is complete", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+	logger.InfoContext(ctx, "created release commit", slog.String("commit", hash), slog.Int("files", len(touched)))
+
+	return nil
+}
+
+// indent prefixes every line of s with two spaces for nesting in dry-run
+// output.
+func indent(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// printPendingDeletions lists the bump files a commit would consume.
+func printPendingDeletions(stdout io.Writer, dir string) error {
+	files, err := workspace.PendingBumpFiles(dir)
+	if err != nil {
+		return cmd.Failed(err)
+	}
+
+	for _, file := range files {
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			file = rel
+		}
+		fmt.Fprintf(stdout, "would delete %s\n", file)
+	}
+
+	return nil
+}
+
+func commitVersionBump(ctx context.Context, runner workspace.Runner, dir string, group workspace.ReleaseGroup, versionStr string, dependencies map[string]string, out io.Writer) error {
+	inv, err := workspace.NewNextInvocation(group, versionStr, dependencies)
 	if err != nil {
 		return err
 	}
 
-	if err := runner.Run(ctx, dir, inv, os.Stdout); err != nil {
+	if err := runner.Run(ctx, dir, inv, out); err != nil {
 		return fmt.Errorf("execute next version command: %w", err)
 	}
 
 	return nil
 }
 
-func commitChangelog(ctx context.Context, runner workspace.Runner, dir string, group workspace.ReleaseGroup, versionStr string, status *workspace.ReleaseGroupStatus) error {
+func commitChangelog(ctx context.Context, runner workspace.Runner, dir string, group workspace.ReleaseGroup, versionStr string, status *workspace.ReleaseGroupStatus, out io.Writer) error {
 	inv, err := workspace.NewChangelogInvocation(group, versionStr, status)
 	if err != nil {
 		return err
 	}
 
-	if err := runner.Run(ctx, dir, inv, os.Stdout); err != nil {
+	if err := runner.Run(ctx, dir, inv, out); err != nil {
 		return fmt.Errorf("execute amend changelog command: %w", err)
 	}
 
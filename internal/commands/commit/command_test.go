@@ -3,35 +3,56 @@ package commit
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
 	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
 )
 
-// scriptedRunner serves current versions and fails the version-bump command
-// for one designated group.
+// scriptedRunner serves current versions (1.0.0 unless overridden per group)
+// and fails one command (the version bump unless failVerb is set) for one
+// designated group.
 type scriptedRunner struct {
 	failGroup string
+	failVerb  workspace.Verb
+	versions  map[string]string
 	calls     []workspace.GroupInvocation
+	mu        sync.Mutex
 }
 
 func (r *scriptedRunner) Run(_ context.Context, _ string, inv workspace.GroupInvocation, stdout io.Writer) error {
+	r.mu.Lock()
 	r.calls = append(r.calls, inv)
+	r.mu.Unlock()
 
-	if r.failGroup != "" && inv.Verb == workspace.VerbNext && slices.Contains(inv.Env, "BUMPER_GROUP="+r.failGroup) {
+	failVerb := r.failVerb
+	if failVerb == "" {
+		failVerb = workspace.VerbNext
+	}
+	if r.failGroup != "" && inv.Verb == failVerb && slices.Contains(inv.Env, "BUMPER_GROUP="+r.failGroup) {
 		return errors.New("boom")
 	}
 
 	if inv.Verb == workspace.VerbCurrent {
-		if _, err := io.WriteString(stdout, "1.0.0\n"); err != nil {
+		version, ok := r.versions[envValue(inv, "BUMPER_GROUP")]
+		if !ok {
+			version = "1.0.0"
+		}
+		if _, err := io.WriteString(stdout, version+"\n"); err != nil {
 			return err
 		}
 	}
@@ -88,7 +109,7 @@ func TestRunFailureKeepsBumpFiles(t *testing.T) {
 	logger := slog.New(slog.DiscardHandler)
 	var stdout bytes.Buffer
 
-	err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout)
+	err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{})
 	if err == nil {
 		t.Fatal("expected an error when the second group fails")
 	}
@@ -131,7 +152,7 @@ func TestRunSuccessDeletesBumpFiles(t *testing.T) {
 	logger := slog.New(slog.DiscardHandler)
 	var stdout bytes.Buffer
 
-	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout); err != nil {
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{}); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -165,7 +186,7 @@ func TestRunRetrySkipsAlreadyReleasedGroups(t *testing.T) {
 	logger := slog.New(slog.DiscardHandler)
 
 	first := &scriptedRunner{failGroup: "b"}
-	if err := run(t.Context(), logger, first, &workspace.FakeProvenance{}, dir, cfg, io.Discard); err == nil {
+	if err := run(t.Context(), logger, first, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err == nil {
 		t.Fatal("expected first attempt to fail")
 	}
 	if len(callsForGroup(first, "a")) == 0 {
@@ -174,7 +195,7 @@ func TestRunRetrySkipsAlreadyReleasedGroups(t *testing.T) {
 
 	second := &scriptedRunner{}
 	var stdout bytes.Buffer
-	if err := run(t.Context(), logger, second, &workspace.FakeProvenance{}, dir, cfg, &stdout); err != nil {
+	if err := run(t.Context(), logger, second, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{}); err != nil {
 		t.Fatalf("unexpected error on retry: %v", err)
 	}
 
@@ -211,7 +232,7 @@ func TestRunBatchChangeRestartsFromScratch(t *testing.T) {
 	logger := slog.New(slog.DiscardHandler)
 
 	first := &scriptedRunner{failGroup: "b"}
-	if err := run(t.Context(), logger, first, &workspace.FakeProvenance{}, dir, cfg, io.Discard); err == nil {
+	if err := run(t.Context(), logger, first, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err == nil {
 		t.Fatal("expected first attempt to fail")
 	}
 
@@ -222,7 +243,7 @@ func TestRunBatchChangeRestartsFromScratch(t *testing.T) {
 	}
 
 	second := &scriptedRunner{}
-	if err := run(t.Context(), logger, second, &workspace.FakeProvenance{}, dir, cfg, io.Discard); err != nil {
+	if err := run(t.Context(), logger, second, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err != nil {
 		t.Fatalf("unexpected error on retry: %v", err)
 	}
 
@@ -249,7 +270,7 @@ func TestRunNoEffectiveBumpsStillCleansUp(t *testing.T) {
 	runner := &scriptedRunner{}
 	logger := slog.New(slog.DiscardHandler)
 
-	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard); err != nil {
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -260,3 +281,749 @@ func TestRunNoEffectiveBumpsStillCleansUp(t *testing.T) {
 		t.Errorf("runner calls = %d, want none when nothing is released", len(runner.calls))
 	}
 }
+
+// envValue returns the value of key in an invocation's environment.
+func envValue(inv workspace.GroupInvocation, key string) string {
+	for _, kv := range inv.Env {
+		if value, ok := strings.CutPrefix(kv, key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// A group in prerelease mode releases the next prerelease on its channel and
+// accumulates the released entries for the eventual graduation.
+func TestRunPrereleaseChannel(t *testing.T) {
+	dir := setupPendingBumps(t, "a")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a")}}
+	logger := slog.New(slog.DiscardHandler)
+
+	state := &workspace.PreState{Groups: map[string]*workspace.PreReleaseGroup{
+		"a": {Mode: workspace.PreModeActive, Tag: "rc", Base: "1.0.0"},
+	}}
+	if err := workspace.SavePreState(dir, state); err != nil {
+		t.Fatalf("save prerelease state: %v", err)
+	}
+
+	runner := &scriptedRunner{}
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	next := slices.IndexFunc(runner.calls, func(inv workspace.GroupInvocation) bool { return inv.Verb == workspace.VerbNext })
+	if next < 0 {
+		t.Fatal("expected a next version invocation")
+	}
+	if got, want := envValue(runner.calls[next], "BUMPER_GROUP_NEXT_VERSION"), "1.1.0-rc.1"; got != want {
+		t.Errorf("next version = %q, want %q", got, want)
+	}
+
+	loaded, err := workspace.LoadPreState(dir)
+	if err != nil {
+		t.Fatalf("load prerelease state: %v", err)
+	}
+	recorded := loaded.Groups["a"]
+	if recorded == nil {
+		t.Fatal("expected group a to stay in prerelease mode")
+	}
+	if recorded.Level != workspace.BumpLevelMinor {
+		t.Errorf("accumulated level = %v, want %v", recorded.Level, workspace.BumpLevelMinor)
+	}
+	if len(recorded.MinorLogs) != 1 || recorded.MinorLogs[0].Content != "change for a" {
+		t.Errorf("accumulated minor logs = %#v, want the released entry", recorded.MinorLogs)
+	}
+}
+
+// Graduating a group releases the final version with every accumulated entry
+// even when no bump files are pending, and leaves prerelease mode.
+func TestRunPrereleaseGraduation(t *testing.T) {
+	dir := setupPendingBumps(t)
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a")}}
+	logger := slog.New(slog.DiscardHandler)
+
+	state := &workspace.PreState{Groups: map[string]*workspace.PreReleaseGroup{
+		"a": {
+			Mode:      workspace.PreModeExit,
+			Tag:       "rc",
+			Base:      "1.0.0",
+			Level:     workspace.BumpLevelMinor,
+			MinorLogs: []workspace.LogEntry{{Content: "feature from rc.1"}},
+		},
+	}}
+	if err := workspace.SavePreState(dir, state); err != nil {
+		t.Fatalf("save prerelease state: %v", err)
+	}
+
+	runner := &scriptedRunner{}
+	var stdout bytes.Buffer
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changelog := slices.IndexFunc(runner.calls, func(inv workspace.GroupInvocation) bool { return inv.Verb == workspace.VerbChangelog })
+	if changelog < 0 {
+		t.Fatal("expected a changelog invocation")
+	}
+	inv := runner.calls[changelog]
+	if got, want := envValue(inv, "BUMPER_GROUP_NEXT_VERSION"), "1.1.0"; got != want {
+		t.Errorf("next version = %q, want %q", got, want)
+	}
+	if !slices.Contains(inv.Argv, "feature from rc.1") {
+		t.Errorf("changelog argv = %v, want the accumulated entry", inv.Argv)
+	}
+
+	if got, want := stdout.String(), "a\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(workspace.PreStateFilename(dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("prerelease state stat = %v, want it removed after graduation", err)
+	}
+}
+
+// A dry run resolves current versions but records every other group command
+// instead of running it, and leaves bump files and the checkpoint untouched.
+func TestRunDryRun(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroup("a"), testGroup("b"),
+	}}
+	runner := &scriptedRunner{}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{dryRun: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, inv := range runner.calls {
+		if inv.Verb != workspace.VerbCurrent {
+			t.Errorf("dry run executed a %s command: %v", inv.Verb, inv.Argv)
+		}
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"a: would release 1.1.0 (minor)",
+		"would run next: true\n    env: BUMPER_GROUP=a BUMPER_GROUP_NEXT_VERSION=1.1.0",
+		"would run changelog: true --group b --minor \"change for b\"",
+		"would delete .bumper/bump-a.md",
+		"would delete .bumper/bump-b.md",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stdout = %q, want it to contain %q", out, want)
+		}
+	}
+
+	if got := pendingBumpFiles(t, dir); len(got) != 2 {
+		t.Errorf("bump files remaining = %v, want both preserved by a dry run", got)
+	}
+	if _, err := os.Stat(workspace.CommitCheckpointFilename(dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("checkpoint stat = %v, want no checkpoint written by a dry run", err)
+	}
+}
+
+func TestRunDryRunTag(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	a := testGroup("a")
+	a.TagFormat = "a/v{{version}}"
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{a, testGroup("b")}}
+	runner := &scriptedRunner{}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{dryRun: true, tag: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "would tag a/v1.1.0\n") {
+		t.Errorf("stdout = %q, want it to preview the tag for a", out)
+	}
+	if strings.Contains(out, "would tag b") {
+		t.Errorf("stdout = %q, want no tag for b, which has no tag_format", out)
+	}
+}
+
+func TestRunTagReportsCreatedTags(t *testing.T) {
+	for _, output := range []string{"", shared.OutputJSON} {
+		t.Run("output "+output, func(t *testing.T) {
+			dir := setupPendingBumps(t, "a", "b")
+			commitWorkspace(t, dir)
+			a := testGroup("a")
+			a.TagFormat = "a/v{{version}}"
+			cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{a, testGroup("b")}}
+			logger := slog.New(slog.DiscardHandler)
+			var stdout bytes.Buffer
+
+			opts := options{gitCommit: true, tag: true, output: output}
+			if err := run(t.Context(), logger, &scriptedRunner{}, &workspace.FakeProvenance{}, dir, cfg, &stdout, opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if output == "" {
+				if got, want := stdout.String(), "a\nb\na/v1.1.0\n"; got != want {
+					t.Errorf("stdout = %q, want %q", got, want)
+				}
+				return
+			}
+
+			var doc shared.Document
+			if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+				t.Fatalf("decode document: %v", err)
+			}
+			tags := map[string]string{}
+			for _, group := range doc.Groups {
+				tags[group.Name] = group.Tag
+			}
+			if want := map[string]string{"a": "a/v1.1.0", "b": ""}; !reflect.DeepEqual(tags, want) {
+				t.Errorf("tags = %v, want %v", tags, want)
+			}
+		})
+	}
+}
+
+// --git-commit refuses to start when the worktree has changes the release
+// did not make, since they would be mixed into the release commit.
+func TestRunGitCommitRequiresCleanWorktree(t *testing.T) {
+	dir := setupPendingBumps(t, "a")
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatalf("init git repository: %v", err)
+	}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a")}}
+	runner := &scriptedRunner{}
+	logger := slog.New(slog.DiscardHandler)
+
+	err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{gitCommit: true})
+	if err == nil {
+		t.Fatal("expected an error for the untracked bump file")
+	}
+	var cmdErr *cmd.CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Unwrap().Error(), ".bumper/bump-a.md") {
+		t.Errorf("error = %v, want it to name the uncommitted file", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("calls = %v, want no group commands run", runner.calls)
+	}
+}
+
+func TestRunWritesManifest(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a"), testGroup("b")}}
+	runner := &scriptedRunner{versions: map[string]string{"b": "2.3.0"}}
+	prov := &workspace.FakeProvenance{Commits: map[string]workspace.Commit{
+		workspace.BumpFilename(dir, "a"): {SHA: "abc1234def5678", When: time.Unix(1700000000, 0)},
+	}}
+	logger := slog.New(slog.DiscardHandler)
+	manifestPath := filepath.Join(t.TempDir(), "release.json")
+
+	if err := run(t.Context(), logger, runner, prov, dir, cfg, io.Discard, options{manifest: manifestPath}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	manifest, err := workspace.LoadReleaseManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []workspace.ReleaseRecord{
+		{Group: "a", PreviousVersion: "1.0.0", Version: "1.1.0", Level: workspace.BumpLevelMinor, Commits: []string{"abc1234def5678"}},
+		{Group: "b", PreviousVersion: "2.3.0", Version: "2.4.0", Level: workspace.BumpLevelMinor, Commits: []string{}},
+	}
+	if !reflect.DeepEqual(manifest.Releases, want) {
+		t.Errorf("releases = %#v, want %#v", manifest.Releases, want)
+	}
+
+	if calls := callsForGroup(runner, "a"); !slices.ContainsFunc(calls, func(inv workspace.GroupInvocation) bool {
+		return inv.Verb == workspace.VerbCat && envValue(inv, "BUMPER_GROUP_VERSION") == "1.1.0"
+	}) {
+		t.Error("expected the changelog section to be read for the released version")
+	}
+}
+
+// Dependencies are released before their dependents, which receive an
+// implied bump, the new dependency versions and a synthesized changelog entry.
+func TestRunReleasesDependenciesFirst(t *testing.T) {
+	dir := setupPendingBumps(t, "z-sdk")
+	cli := testGroup("a-cli")
+	cli.DependsOn = []workspace.Dependency{{Group: "z-sdk"}}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{cli, testGroup("z-sdk")}}
+	runner := &scriptedRunner{}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := stdout.String(), "z-sdk\na-cli\n"; got != want {
+		t.Errorf("stdout = %q, want dependency released first %q", got, want)
+	}
+
+	calls := callsForGroup(runner, "a-cli")
+	next := slices.IndexFunc(calls, func(inv workspace.GroupInvocation) bool { return inv.Verb == workspace.VerbNext })
+	if next < 0 {
+		t.Fatal("expected the dependent group to be released")
+	}
+	if got, want := envValue(calls[next], "BUMPER_GROUP_NEXT_VERSION"), "1.0.1"; got != want {
+		t.Errorf("dependent next version = %q, want %q", got, want)
+	}
+	if got, want := envValue(calls[next], "BUMPER_GROUP_DEPENDENCIES"), "z-sdk=1.1.0"; got != want {
+		t.Errorf("dependencies = %q, want %q", got, want)
+	}
+
+	changelog := slices.IndexFunc(calls, func(inv workspace.GroupInvocation) bool { return inv.Verb == workspace.VerbChangelog })
+	if changelog < 0 || !slices.Contains(calls[changelog].Argv, "Updated dependency z-sdk to 1.1.0") {
+		t.Errorf("dependent changelog invocation = %v, want a synthesized dependency entry", calls)
+	}
+}
+
+// Every group of a fixed set is released under one version computed from the
+// highest current version in the set, even without pending bumps of its own.
+func TestRunFixedSetSharesOneVersion(t *testing.T) {
+	dir := setupPendingBumps(t, "a")
+	cfg := &workspace.Config{
+		Fixed:  [][]string{{"a", "b"}},
+		Groups: []workspace.ReleaseGroup{testGroup("a"), testGroup("b"), testGroup("c")},
+	}
+	runner := &scriptedRunner{versions: map[string]string{"a": "1.0.0", "b": "1.2.0"}}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := stdout.String(), "a\nb\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	for _, group := range []string{"a", "b"} {
+		calls := callsForGroup(runner, group)
+		next := slices.IndexFunc(calls, func(inv workspace.GroupInvocation) bool { return inv.Verb == workspace.VerbNext })
+		if next < 0 {
+			t.Errorf("%s: expected a next version invocation", group)
+			continue
+		}
+		if got, want := envValue(calls[next], "BUMPER_GROUP_NEXT_VERSION"), "1.3.0"; got != want {
+			t.Errorf("%s: next version = %q, want %q", group, got, want)
+		}
+	}
+}
+
+// fileRunner writes each group's new version to <group>.txt, as a version
+// command would, before delegating to a scriptedRunner.
+type fileRunner struct {
+	*scriptedRunner
+}
+
+func (r fileRunner) Run(ctx context.Context, dir string, inv workspace.GroupInvocation, stdout io.Writer) error {
+	if inv.Verb == workspace.VerbNext {
+		filename := filepath.Join(dir, envValue(inv, "BUMPER_GROUP")+".txt")
+		if err := os.WriteFile(filename, []byte(envValue(inv, "BUMPER_GROUP_NEXT_VERSION")+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return r.scriptedRunner.Run(ctx, dir, inv, stdout)
+}
+
+// --atomic undoes the groups released before a failure, leaving the
+// repository as it started.
+// commitWorkspace commits everything in dir to a new git repository with a
+// committer configured, as releases with --git-commit and --tag need.
+func commitWorkspace(t *testing.T, dir string) {
+	t.Helper()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init git repository: %v", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("read git config: %v", err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("write git config: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	if err := worktree.AddGlob("."); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	if _, err := worktree.Commit("initial commit", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+}
+
+func TestRunAtomicRestoresWorktree(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("1.0.0\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	commitWorkspace(t, dir)
+
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a"), testGroup("b")}}
+	runner := fileRunner{&scriptedRunner{failGroup: "b"}}
+	logger := slog.New(slog.DiscardHandler)
+
+	err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{atomic: true})
+	if err == nil {
+		t.Fatal("expected an error when group b fails")
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatalf("read a.txt: %v", err)
+	}
+	if string(content) != "1.0.0\n" {
+		t.Errorf("a.txt = %q, want the release of group a undone", content)
+	}
+	if _, err := os.Stat(workspace.CommitCheckpointFilename(dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("checkpoint stat error = %v, want no checkpoint left behind", err)
+	}
+
+	changes, err := workspace.WorktreeChanges(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("worktree changes = %v, want a clean worktree", changes)
+	}
+}
+
+func TestRunAtomicRequiresGit(t *testing.T) {
+	dir := setupPendingBumps(t, "a")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a")}}
+	runner := &scriptedRunner{}
+	logger := slog.New(slog.DiscardHandler)
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{atomic: true}); err == nil {
+		t.Fatal("expected an error outside a git repository")
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("calls = %v, want no group commands run", runner.calls)
+	}
+}
+
+// A group interrupted after next_cmd resumes with its recorded version at
+// the changelog step, rather than bumping the already bumped version again.
+func TestRunResumesInterruptedStep(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a"), testGroup("b")}}
+	logger := slog.New(slog.DiscardHandler)
+
+	first := &scriptedRunner{failGroup: "b", failVerb: workspace.VerbChangelog}
+	if err := run(t.Context(), logger, first, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err == nil {
+		t.Fatal("expected first attempt to fail")
+	}
+
+	checkpoint, err := workspace.LoadCommitCheckpoint(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]workspace.GroupProgress{"b": {Version: "1.1.0", Previous: "1.0.0", Step: workspace.CommitStepNext}}
+	if !reflect.DeepEqual(checkpoint.Progress, want) {
+		t.Errorf("progress = %#v, want %#v", checkpoint.Progress, want)
+	}
+
+	// next_cmd already moved b to 1.1.0.
+	second := &scriptedRunner{versions: map[string]string{"b": "1.1.0"}}
+	var stdout bytes.Buffer
+	if err := run(t.Context(), logger, second, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{resume: true}); err != nil {
+		t.Fatalf("unexpected error on resume: %v", err)
+	}
+
+	calls := callsForGroup(second, "b")
+	if len(calls) != 1 || calls[0].Verb != workspace.VerbChangelog {
+		t.Fatalf("group b calls = %v, want only the changelog command", calls)
+	}
+	if got := envValue(calls[0], "BUMPER_GROUP_NEXT_VERSION"); got != "1.1.0" {
+		t.Errorf("changelog version = %q, want the recorded 1.1.0", got)
+	}
+
+	wantReport := "Interrupted commit of 2 bump files (.bumper/commit-checkpoint.toml):\n" +
+		"  a: released 1.1.0\n" +
+		"  b: releasing 1.1.0, version applied, changelog not amended yet\n"
+	if got := stdout.String(); !strings.HasPrefix(got, wantReport) {
+		t.Errorf("stdout = %q, want it to start with %q", got, wantReport)
+	}
+}
+
+// The previous versions are recorded whatever the flags, so a retry with
+// --manifest reports those of the groups an earlier attempt released.
+func TestRunManifestAfterRetryKeepsPreviousVersions(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a"), testGroup("b")}}
+	logger := slog.New(slog.DiscardHandler)
+
+	first := &scriptedRunner{failGroup: "b", failVerb: workspace.VerbChangelog}
+	if err := run(t.Context(), logger, first, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err == nil {
+		t.Fatal("expected first attempt to fail")
+	}
+
+	// next_cmd already moved both groups to 1.1.0.
+	second := &scriptedRunner{versions: map[string]string{"a": "1.1.0", "b": "1.1.0"}}
+	manifestPath := filepath.Join(t.TempDir(), "release.json")
+	if err := run(t.Context(), logger, second, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{manifest: manifestPath}); err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+
+	manifest, err := workspace.LoadReleaseManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, record := range manifest.Releases {
+		if record.PreviousVersion != "1.0.0" || record.Version != "1.1.0" {
+			t.Errorf("%s: released %q after %q, want 1.1.0 after 1.0.0", record.Group, record.Version, record.PreviousVersion)
+		}
+	}
+	if len(manifest.Releases) != 2 {
+		t.Errorf("releases = %#v, want a and b", manifest.Releases)
+	}
+}
+
+func TestRunResumeRequiresCheckpoint(t *testing.T) {
+	dir := setupPendingBumps(t, "a")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a")}}
+	runner := &scriptedRunner{}
+	logger := slog.New(slog.DiscardHandler)
+
+	err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{resume: true})
+	if !errors.Is(err, errNoCheckpoint) {
+		t.Errorf("error = %v, want errNoCheckpoint", err)
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("calls = %v, want no group commands run", runner.calls)
+	}
+}
+
+func TestAbort(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("a"), testGroup("b")}}
+	logger := slog.New(slog.DiscardHandler)
+
+	var stdout bytes.Buffer
+	if err := abort(t.Context(), logger, dir, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := stdout.String(), "No interrupted commit to abort.\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	if err := run(t.Context(), logger, &scriptedRunner{failGroup: "b"}, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{}); err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+
+	stdout.Reset()
+	if err := abort(t.Context(), logger, dir, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{"  a: released 1.1.0\n", "  b: releasing 1.1.0, version computed, next_cmd not run yet\n", "Aborted:"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("stdout = %q, want it to contain %q", stdout.String(), line)
+		}
+	}
+	if _, err := os.Stat(workspace.CommitCheckpointFilename(dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("checkpoint stat error = %v, want the checkpoint removed", err)
+	}
+	if got := pendingBumpFiles(t, dir); len(got) != 2 {
+		t.Errorf("bump files remaining = %v, want both kept", got)
+	}
+}
+
+// overlapRunner wraps scriptedRunner to record the groups whose next or
+// changelog commands run at the same time. With barrier set, next commands
+// wait until that many groups are running such commands at once.
+type overlapRunner struct {
+	scriptedRunner
+	barrier  int
+	mu       sync.Mutex
+	active   []string
+	overlaps []string
+}
+
+func (r *overlapRunner) Run(ctx context.Context, dir string, inv workspace.GroupInvocation, stdout io.Writer) error {
+	if inv.Verb != workspace.VerbNext && inv.Verb != workspace.VerbChangelog {
+		return r.scriptedRunner.Run(ctx, dir, inv, stdout)
+	}
+
+	group := envValue(inv, "BUMPER_GROUP")
+	r.mu.Lock()
+	for _, other := range r.active {
+		r.overlaps = append(r.overlaps, other+"+"+group)
+	}
+	r.active = append(r.active, group)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.active = slices.DeleteFunc(r.active, func(g string) bool { return g == group })
+		r.mu.Unlock()
+	}()
+
+	if r.barrier > 0 && inv.Verb == workspace.VerbNext {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			r.mu.Lock()
+			n := len(r.active)
+			r.mu.Unlock()
+			if n >= r.barrier {
+				break
+			}
+			if time.Now().After(deadline) {
+				return errors.New("groups were not released concurrently")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	} else {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return r.scriptedRunner.Run(ctx, dir, inv, stdout)
+}
+
+// testGroupWithChangelog returns a group whose builtin commands write only
+// files of its own, so it may be released alongside other groups.
+func testGroupWithChangelog(name string) workspace.ReleaseGroup {
+	g := testGroup(name)
+	g.ChangelogPath = name + "/CHANGELOG.md"
+	g.ChangelogCMD = []string{"bumper", "builtins", "amendlog:default"}
+	g.NextCMD = []string{"bumper", "builtins", "next:file", "--path", name + "/VERSION"}
+	return g
+}
+
+func TestRunJobsReleasesGroupsConcurrently(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroupWithChangelog("a"), testGroupWithChangelog("b"),
+	}}
+	runner := &overlapRunner{barrier: 2}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{jobs: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := stdout.String(), "a\nb\n"; got != want {
+		t.Errorf("stdout = %q, want groups listed in release order %q", got, want)
+	}
+	if got := pendingBumpFiles(t, dir); len(got) != 0 {
+		t.Errorf("bump files remaining = %v, want none after a successful commit", got)
+	}
+}
+
+// Groups writing the same changelog, and groups depending on each other,
+// never run at the same time.
+func TestRunJobsWaitsForDependenciesAndSharedFiles(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b", "c", "d")
+	c := testGroupWithChangelog("c")
+	c.DependsOn = []workspace.Dependency{{Group: "a"}}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroup("a"), testGroup("b"), c, testGroupWithChangelog("d"),
+	}}
+	runner := &overlapRunner{}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{jobs: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, overlap := range runner.overlaps {
+		switch overlap {
+		case "a+b", "b+a", "a+c", "c+a":
+			t.Errorf("groups %s ran at the same time", overlap)
+		}
+	}
+	if got, want := stdout.String(), "a\nb\nc\nd\n"; got != want {
+		t.Errorf("stdout = %q, want groups listed in release order %q", got, want)
+	}
+	if got, want := envValue(callsForGroup(&runner.scriptedRunner, "c")[1], "BUMPER_GROUP_DEPENDENCIES"), "a=1.1.0"; got != want {
+		t.Errorf("dependencies = %q, want %q", got, want)
+	}
+}
+
+// A group with its own next_cmd may write any file, so it runs alone.
+func TestRunJobsRunsGroupsWithCustomCommandsAlone(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b", "c")
+	b := testGroupWithChangelog("b")
+	b.NextCMD = []string{"./next.sh"}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroupWithChangelog("a"), b, testGroupWithChangelog("c"),
+	}}
+	runner := &overlapRunner{}
+	logger := slog.New(slog.DiscardHandler)
+
+	if err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{jobs: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, overlap := range runner.overlaps {
+		if strings.Contains(overlap, "b") {
+			t.Errorf("groups %s ran at the same time", overlap)
+		}
+	}
+}
+
+func TestRunJobsStopsStartingGroupsAfterFailure(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b", "c")
+	c := testGroupWithChangelog("c")
+	c.DependsOn = []workspace.Dependency{{Group: "b"}}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroupWithChangelog("a"), testGroupWithChangelog("b"), c,
+	}}
+	runner := &overlapRunner{scriptedRunner: scriptedRunner{failGroup: "b"}}
+	logger := slog.New(slog.DiscardHandler)
+
+	err := run(t.Context(), logger, runner, &workspace.FakeProvenance{}, dir, cfg, io.Discard, options{jobs: 3})
+	var cmdErr *cmd.CommandError
+	if !errors.As(err, &cmdErr) || !strings.Contains(cmdErr.Unwrap().Error(), "release group b") {
+		t.Fatalf("error = %v, want release group b to fail", err)
+	}
+
+	if calls := callsForGroup(&runner.scriptedRunner, "c"); len(calls) != 0 {
+		t.Errorf("calls = %v, want the dependent group not started", calls)
+	}
+	checkpoint, err := workspace.LoadCommitCheckpoint(dir)
+	if err != nil {
+		t.Fatalf("load checkpoint: %v", err)
+	}
+	if got, want := checkpoint.Released, map[string]string{"a": "1.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("released = %v, want %v", got, want)
+	}
+	if got := pendingBumpFiles(t, dir); len(got) != 3 {
+		t.Errorf("bump files remaining = %v, want all 3 preserved", got)
+	}
+}
+
+// Each group's dry-run preview is printed in one piece.
+func TestRunJobsDryRunKeepsGroupOutputTogether(t *testing.T) {
+	dir := setupPendingBumps(t, "a", "b")
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroupWithChangelog("a"), testGroupWithChangelog("b"),
+	}}
+	logger := slog.New(slog.DiscardHandler)
+	var stdout bytes.Buffer
+
+	if err := run(t.Context(), logger, &scriptedRunner{}, &workspace.FakeProvenance{}, dir, cfg, &stdout, options{dryRun: true, jobs: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, group := range []string{"a", "b"} {
+		want := group + ": would release 1.1.0 (minor)\n" +
+			"  would run next: bumper builtins next:file --path " + group + "/VERSION\n" +
+			"    env: BUMPER_GROUP=" + group + " BUMPER_GROUP_NEXT_VERSION=1.1.0\n" +
+			"  would run changelog: bumper builtins amendlog:default --group " + group
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("stdout = %q, want it to contain %q", stdout.String(), want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package commit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+// releaser runs the version and changelog commands of the groups in a batch,
+// recording each step in the checkpoint. Groups may be released from several
+// goroutines: mu guards the checkpoint and the prerelease state.
+type releaser struct {
+	logger        *slog.Logger
+	runner        workspace.Runner
+	dir           string
+	cfgGroups     map[string]workspace.ReleaseGroup
+	statuses      map[string]*workspace.ReleaseGroupStatus
+	pre           *workspace.PreState
+	checkpoint    *workspace.CommitCheckpoint
+	fixedVersions map[string]string
+	opts          options
+	preview       io.Writer
+	commandOut    io.Writer
+
+	mu sync.Mutex
+}
+
+// groupOutput buffers the output of a group released alongside others, so it
+// is printed in one piece once the group is done.
+type groupOutput struct {
+	preview bytes.Buffer
+	command bytes.Buffer
+	stderr  bytes.Buffer
+}
+
+// releaseAll releases the groups in queue, which is in release order. With
+// jobs above 1, up to jobs groups are released at once: a group starts once
+// the groups it depends on are released and no running group writes the same
+// files, see workspace.ReleaseGroup.WrittenFiles. A group whose commands may
+// write files that are not known runs on its own. After a failure no further
+// group starts, and the first error is returned once the running groups are
+// done.
+func (r *releaser) releaseAll(ctx context.Context, queue []string, jobs int) error {
+	if jobs <= 1 {
+		for _, groupName := range queue {
+			if err := r.release(ctx, groupName, r.preview, r.commandOut); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type result struct {
+		groupName string
+		out       *groupOutput
+		err       error
+	}
+	results := make(chan result)
+
+	pending := make(map[string]bool, len(queue))
+	written := make(map[string][]string, len(queue))
+	exclusive := map[string]bool{}
+	for _, groupName := range queue {
+		pending[groupName] = true
+		files, known := r.cfgGroups[groupName].WrittenFiles(r.dir)
+		written[groupName] = files
+		exclusive[groupName] = !known
+	}
+	busy := map[string]bool{}
+	running := 0
+	runningExclusive := false
+
+	ready := func(groupName string) bool {
+		if runningExclusive || (exclusive[groupName] && running > 0) {
+			return false
+		}
+		for _, dep := range r.cfgGroups[groupName].DependsOn {
+			if pending[dep.Group] {
+				return false
+			}
+		}
+		for _, file := range written[groupName] {
+			if busy[file] {
+				return false
+			}
+		}
+		return true
+	}
+
+	waiting := slices.Clone(queue)
+	var firstErr error
+	for {
+		for i := 0; firstErr == nil && running < jobs && i < len(waiting); {
+			groupName := waiting[i]
+			if !ready(groupName) {
+				i++
+				continue
+			}
+			waiting = slices.Delete(waiting, i, i+1)
+			for _, file := range written[groupName] {
+				busy[file] = true
+			}
+			running++
+			runningExclusive = exclusive[groupName]
+
+			go func() {
+				out := &groupOutput{}
+				err := r.release(workspace.WithStderr(ctx, &out.stderr), groupName, &out.preview, &out.command)
+				results <- result{groupName: groupName, out: out, err: err}
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		runningExclusive = false
+		delete(pending, res.groupName)
+		for _, file := range written[res.groupName] {
+			delete(busy, file)
+		}
+
+		_, _ = res.out.preview.WriteTo(r.preview)
+		_, _ = res.out.command.WriteTo(r.commandOut)
+		_, _ = res.out.stderr.WriteTo(workspace.StderrFrom(ctx))
+
+		if res.err != nil && firstErr == nil {
+			firstErr = res.err
+		}
+	}
+
+	return firstErr
+}
+
+// release runs the remaining steps of one group's release, writing its
+// dry-run preview to preview and the output of its commands to commandOut.
+func (r *releaser) release(ctx context.Context, groupName string, preview io.Writer, commandOut io.Writer) error {
+	logger, dir, opts := r.logger, r.dir, r.opts
+	g := r.cfgGroups[groupName]
+	status := r.statuses[groupName]
+
+	runner := r.runner
+	if opts.dryRun {
+		runner = &workspace.RecordingRunner{Runner: runner, Out: preview}
+	}
+
+	r.mu.Lock()
+	version, released := r.checkpoint.Released[groupName]
+	progress, resumed := r.checkpoint.Progress[groupName]
+	preGroup := r.pre.Groups[groupName]
+	r.mu.Unlock()
+
+	if released {
+		logger.InfoContext(ctx, "skipping group released by a previous attempt at this batch", slog.String("group", groupName), slog.String("version", version))
+		if opts.dryRun {
+			fmt.Fprintf(preview, "%s: already released as %s by a previous attempt\n", groupName, version)
+		}
+		return nil
+	}
+
+	if resumed {
+		logger.InfoContext(ctx, "resuming group interrupted by a previous attempt at this batch", slog.String("group", groupName), slog.String("version", progress.Version), slog.String("step", string(progress.Step)))
+	} else {
+		// The current version is kept as the previous version, so a
+		// manifest written after a retry still has it.
+		current, err := workspace.GetCurrentVersion(ctx, runner, dir, g)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to get current version", slog.String("group", groupName), slog.String("error", err.Error()))
+			return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+		}
+
+		nextVersion, fixed := r.fixedVersions[groupName]
+		if !fixed {
+			nextVersion, err = workspace.ComputeNextVersion(current, status.Level, preGroup)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to get next version", slog.String("group", groupName), slog.String("error", err.Error()))
+				return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+			}
+		}
+
+		progress = workspace.GroupProgress{Version: nextVersion, Previous: current.String(), Step: workspace.CommitStepVersion}
+		if err := r.recordProgress(groupName, progress); err != nil {
+			logger.ErrorContext(ctx, "failed to save commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+	}
+	nextVersion := progress.Version
+
+	if opts.dryRun {
+		if resumed {
+			fmt.Fprintf(preview, "%s: would resume releasing %s (%s) after step %s\n", groupName, nextVersion, status.Level, progress.Step)
+		} else {
+			fmt.Fprintf(preview, "%s: would release %s (%s)\n", groupName, nextVersion, status.Level)
+		}
+	}
+
+	// The groups this one depends on are released by now; other groups may
+	// still be adding their versions.
+	r.mu.Lock()
+	releasedVersions := maps.Clone(r.checkpoint.Released)
+	r.mu.Unlock()
+
+	status.AddDependencyEntries(releasedVersions)
+
+	if !progress.Done(workspace.CommitStepNext) {
+		err := commitVersionBump(ctx, runner, dir, g, nextVersion, status.DependencyVersions(releasedVersions), commandOut)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to commit version bump", slog.String("group", groupName), slog.String("version", nextVersion), slog.String("error", err.Error()))
+			return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+		}
+
+		progress.Step = workspace.CommitStepNext
+		if err := r.recordProgress(groupName, progress); err != nil {
+			logger.ErrorContext(ctx, "failed to save commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+	}
+
+	if !progress.Done(workspace.CommitStepChangelog) {
+		err := commitChangelog(ctx, runner, dir, g, nextVersion, status, commandOut)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to commit changelog", slog.String("group", groupName), slog.String("version", nextVersion), slog.String("error", err.Error()))
+			return cmd.Failed(fmt.Errorf("release group %s: %w", groupName, err))
+		}
+
+		progress.Step = workspace.CommitStepChangelog
+		if err := r.recordProgress(groupName, progress); err != nil {
+			logger.ErrorContext(ctx, "failed to save commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if progress.Previous != "" {
+		if r.checkpoint.Previous == nil {
+			r.checkpoint.Previous = map[string]string{}
+		}
+		r.checkpoint.Previous[groupName] = progress.Previous
+	}
+
+	if opts.dryRun {
+		// Track the release in memory only, so dependents still see its
+		// version.
+		r.checkpoint.Released[groupName] = nextVersion
+		delete(r.checkpoint.Progress, groupName)
+		return nil
+	}
+
+	if p, ok := r.pre.Groups[groupName]; ok {
+		if p.Mode == workspace.PreModeExit {
+			delete(r.pre.Groups, groupName)
+		} else {
+			p.Record(status)
+		}
+		if err := workspace.SavePreState(dir, r.pre); err != nil {
+			logger.ErrorContext(ctx, "failed to save prerelease state", slog.String("dir", dir), slog.String("error", err.Error()))
+			return cmd.Failed(err)
+		}
+	}
+
+	// Record the group as released before moving on, so a failure in a
+	// later group never re-releases this one on retry.
+	r.checkpoint.Released[groupName] = nextVersion
+	delete(r.checkpoint.Progress, groupName)
+	if err := workspace.SaveCommitCheckpoint(dir, r.checkpoint); err != nil {
+		logger.ErrorContext(ctx, "failed to save commit checkpoint", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	return nil
+}
+
+// recordProgress records a completed step of a group's release in the
+// checkpoint, saving it unless this is a dry run.
+func (r *releaser) recordProgress(groupName string, progress workspace.GroupProgress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.checkpoint.Progress == nil {
+		r.checkpoint.Progress = map[string]workspace.GroupProgress{}
+	}
+	r.checkpoint.Progress[groupName] = progress
+	if r.opts.dryRun {
+		return nil
+	}
+
+	return workspace.SaveCommitCheckpoint(r.dir, r.checkpoint)
+}
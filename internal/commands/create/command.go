@@ -3,6 +3,9 @@ package create
 import (
 	"context"
 	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -24,6 +27,12 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 		},
 		Flags: []cli.Flag{
 			shared.NewDirFlag(),
+			&cli.StringFlag{
+				Name: "changelog-path",
+				Usage: "The changelog file of the new groups, relative to the workspace root. A {{group}} placeholder is" +
+					" replaced by each group's name (defaults to <group>/CHANGELOG.md when a <group> directory exists," +
+					" otherwise CHANGELOG.md)",
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
@@ -44,12 +53,13 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				}
 
 				groups = append(groups, workspace.ReleaseGroup{
-					Name:         name,
-					DisplayName:  name,
-					ChangelogCMD: []string{"bumper", "builtins", "amendlog:default"},
-					CatCMD:       []string{"bumper", "builtins", "cat:default"},
-					CurrentCMD:   []string{"bumper", "builtins", "current:default"},
-					NextCMD:      []string{"bumper", "builtins", "next:default"},
+					Name:          name,
+					DisplayName:   name,
+					ChangelogPath: changelogPath(res.Dir, name, c.String("changelog-path")),
+					ChangelogCMD:  []string{"bumper", "builtins", "amendlog:default"},
+					CatCMD:        []string{"bumper", "builtins", "cat:default"},
+					CurrentCMD:    []string{"bumper", "builtins", "current:default"},
+					NextCMD:       []string{"bumper", "builtins", "next:default"},
 				})
 			}
 
@@ -71,3 +81,22 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 		},
 	}
 }
+
+// groupPlaceholder is replaced by the group name in --changelog-path.
+const groupPlaceholder = "{{group}}"
+
+// changelogPath picks the changelog_path of a new group. Without a format, a
+// group named after a directory in the workspace root gets a changelog in
+// that directory; other groups share the default CHANGELOG.md and are left
+// without a changelog_path.
+func changelogPath(dir string, name string, format string) string {
+	if format != "" {
+		return strings.ReplaceAll(format, groupPlaceholder, name)
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+		return path.Join(name, workspace.DefaultChangelogPath)
+	}
+
+	return ""
+}
@@ -0,0 +1,34 @@
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangelogPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "api"), 0o755); err != nil {
+		t.Fatalf("create package dir: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		group  string
+		format string
+		want   string
+	}{
+		{name: "package directory", group: "api", want: "api/CHANGELOG.md"},
+		{name: "no package directory", group: "web", want: ""},
+		{name: "format", group: "web", format: "packages/{{group}}/CHANGELOG.md", want: "packages/web/CHANGELOG.md"},
+		{name: "format without placeholder", group: "api", format: "CHANGELOG.md", want: "CHANGELOG.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changelogPath(dir, tt.group, tt.format); got != tt.want {
+				t.Errorf("changelogPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
 	"github.com/disintegrator/bumper/internal/commands/shared"
 	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
@@ -34,13 +35,20 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				return err
 			}
 
-			currentVersion, err := workspace.GetCurrentVersion(ctx, workspace.ExecRunner{}, res.Dir, group)
+			currentVersion, err := workspace.GetCurrentVersion(ctx, builtins.NewRunner(logger, res), res.Dir, group)
 			if err != nil {
 				logger.ErrorContext(ctx, "failed to get current version", slog.String("group", group.Name), slog.String("error", err.Error()))
 				return cmd.Failed(err)
 			}
 
-			fmt.Println(currentVersion)
+			out := c.Root().Writer
+			if shared.OutputFlag(c) == shared.OutputJSON {
+				return shared.WriteDocument(out, logger, &shared.Document{Groups: []shared.GroupDocument{
+					{Name: group.Name, CurrentVersion: currentVersion.String()},
+				}})
+			}
+
+			fmt.Fprintln(out, currentVersion)
 
 			return nil
 		},
@@ -0,0 +1,166 @@
+package infer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/goccy/go-yaml"
+	"github.com/urfave/cli/v3"
+)
+
+func NewCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "infer",
+		Usage: "Create bump files from the conventional commits made since each release group's last release tag",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			shared.NewLockTimeoutFlag(),
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the inferred bumps and the release levels they add up to, without writing bump files",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			if !c.Bool("dry-run") {
+				lock, err := shared.Lock(ctx, logger, res.Dir, shared.LockTimeoutFlag(c))
+				if err != nil {
+					return err
+				}
+				defer shared.Unlock(ctx, logger, lock)
+			}
+
+			opts := options{dryRun: c.Bool("dry-run"), output: shared.OutputFlag(c)}
+			return run(ctx, logger, workspace.NewGitHistory(logger, res.Dir), res.Dir, res.Config, c.Root().Writer, opts)
+		},
+	}
+}
+
+// options are the infer command's behaviour switches.
+type options struct {
+	// dryRun prints the inferred bumps instead of writing bump files.
+	dryRun bool
+	// output is the --output format.
+	output string
+}
+
+// run infers bumps from history and writes one bump file per commit. Bump
+// files are named after their commit, so commits inferred by an earlier run
+// are not written twice. Under --output json the bump files and the release
+// levels they add up to are printed as a shared.Document.
+func run(
+	ctx context.Context,
+	logger *slog.Logger,
+	history workspace.CommitHistory,
+	dir string,
+	cfg *workspace.Config,
+	stdout io.Writer,
+	opts options,
+) error {
+	jsonOutput := opts.output == shared.OutputJSON
+	doc := &shared.Document{DryRun: opts.dryRun}
+
+	bumps, err := workspace.InferBumps(ctx, logger, history, cfg)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to infer bumps from commit history", slog.String("dir", dir), slog.String("error", err.Error()))
+		return cmd.Failed(err)
+	}
+
+	if len(bumps) == 0 {
+		logger.InfoContext(ctx, "no releasable conventional commits found", slog.String("dir", dir))
+		if jsonOutput {
+			return shared.WriteDocument(stdout, logger, doc)
+		}
+		return nil
+	}
+
+	var written []workspace.ParsedBump
+	for _, bump := range bumps {
+		filename := workspace.BumpFilename(dir, "commit-"+bump.Commit.SHA[:min(7, len(bump.Commit.SHA))])
+		rel := filename
+		if r, err := filepath.Rel(dir, filename); err == nil {
+			rel = r
+		}
+
+		if !opts.dryRun {
+			created, err := writeBumpFile(filename, bump)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to write bump file", slog.String("file", filename), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+			if !created {
+				logger.InfoContext(ctx, "skipping commit inferred by a previous run", slog.String("commit", bump.Commit.SHA), slog.String("file", rel))
+				continue
+			}
+		}
+
+		written = append(written, bump)
+		doc.BumpFiles = append(doc.BumpFiles, rel)
+		switch {
+		case jsonOutput:
+		case opts.dryRun:
+			fmt.Fprintf(stdout, "would write %s: %s\n", rel, bump.Message)
+		default:
+			fmt.Fprintln(stdout, rel)
+		}
+	}
+
+	if !jsonOutput && !opts.dryRun {
+		return nil
+	}
+
+	statuses := workspace.SquashBumps(ctx, logger, written, cfg)
+	for _, group := range cfg.Groups {
+		status, ok := statuses[group.Name]
+		if !ok || status.Level == workspace.BumpLevelNone {
+			continue
+		}
+		if jsonOutput {
+			doc.Groups = append(doc.Groups, shared.GroupDocument{Name: group.Name, Level: status.Level})
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", group.Name, status.Level)
+	}
+
+	if jsonOutput {
+		return shared.WriteDocument(stdout, logger, doc)
+	}
+
+	return nil
+}
+
+// writeBumpFile writes bump as a bump file, reporting false without changes
+// when the file already exists.
+func writeBumpFile(filename string, bump workspace.ParsedBump) (bool, error) {
+	fm, err := yaml.Marshal(bump.Levels)
+	if err != nil {
+		return false, fmt.Errorf("marshal bumps to front matter: %w", err)
+	}
+
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	switch {
+	case errors.Is(err, os.ErrExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "---\n%s---\n\n%s\n", fm, bump.Message); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
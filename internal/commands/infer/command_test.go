@@ -0,0 +1,126 @@
+package infer
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+func testGroup(name string) workspace.ReleaseGroup {
+	return workspace.ReleaseGroup{
+		Name:         name,
+		ChangelogCMD: []string{"true"},
+		CatCMD:       []string{"true"},
+		CurrentCMD:   []string{"true"},
+		NextCMD:      []string{"true"},
+	}
+}
+
+func TestRunWritesOneBumpFilePerCommit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(workspace.Dir(dir), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("api")}}
+	history := &workspace.FakeCommitHistory{Commits: map[string][]workspace.HistoryCommit{
+		"api": {
+			{SHA: "abc1234def", When: time.Unix(1700000000, 0), Message: "feat: add search"},
+			{SHA: "def5678abc", When: time.Unix(1700000100, 0), Message: "chore: tidy"},
+		},
+	}}
+	logger := slog.New(slog.DiscardHandler)
+
+	var stdout bytes.Buffer
+	if err := run(t.Context(), logger, history, dir, cfg, &stdout, options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := stdout.String(), ".bumper/bump-commit-abc1234.md\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".bumper", "bump-commit-abc1234.md"))
+	if err != nil {
+		t.Fatalf("read bump file: %v", err)
+	}
+	if want := "---\napi: minor\n---\n\nadd search\n"; string(content) != want {
+		t.Errorf("bump file = %q, want %q", content, want)
+	}
+
+	// A second run leaves the commits inferred by the first alone.
+	stdout.Reset()
+	if err := run(t.Context(), logger, history, dir, cfg, &stdout, options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want no new bump files", stdout.String())
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(workspace.Dir(dir), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("api"), testGroup("web")}}
+	history := &workspace.FakeCommitHistory{Commits: map[string][]workspace.HistoryCommit{
+		"api": {{SHA: "abc1234def", When: time.Unix(1700000000, 0), Message: "feat(api)!: new auth"}},
+	}}
+	logger := slog.New(slog.DiscardHandler)
+
+	var stdout bytes.Buffer
+	if err := run(t.Context(), logger, history, dir, cfg, &stdout, options{dryRun: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"would write .bumper/bump-commit-abc1234.md: new auth\n", "api: major\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stdout = %q, want it to contain %q", out, want)
+		}
+	}
+
+	matches, err := filepath.Glob(workspace.BumpFilename(dir, "*"))
+	if err != nil {
+		t.Fatalf("glob bump files: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("bump files = %v, want none written by a dry run", matches)
+	}
+}
+
+func TestRunJSONOutput(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(workspace.Dir(dir), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{testGroup("api"), testGroup("web")}}
+	history := &workspace.FakeCommitHistory{Commits: map[string][]workspace.HistoryCommit{
+		"api": {{SHA: "abc1234def", When: time.Unix(1700000000, 0), Message: "fix(api): handle timeouts"}},
+	}}
+	logger := slog.New(slog.DiscardHandler)
+
+	var stdout bytes.Buffer
+	if err := run(t.Context(), logger, history, dir, cfg, &stdout, options{output: shared.OutputJSON}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc shared.Document
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		t.Fatalf("stdout = %q, want a JSON document: %v", stdout.String(), err)
+	}
+	if want := []string{".bumper/bump-commit-abc1234.md"}; !reflect.DeepEqual(doc.BumpFiles, want) {
+		t.Errorf("bump files = %q, want %q", doc.BumpFiles, want)
+	}
+	if want := []shared.GroupDocument{{Name: "api", Level: workspace.BumpLevelPatch}}; !reflect.DeepEqual(doc.Groups, want) {
+		t.Errorf("groups = %+v, want %+v", doc.Groups, want)
+	}
+}
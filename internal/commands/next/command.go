@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
 	"github.com/disintegrator/bumper/internal/commands/shared"
 	"github.com/disintegrator/bumper/internal/workspace"
 	"github.com/urfave/cli/v3"
@@ -40,19 +41,39 @@ func NewCommand(logger *slog.Logger) *cli.Command {
 				return cmd.Failed(err)
 			}
 
+			pre, err := workspace.LoadPreState(res.Dir)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to load prerelease state", slog.String("dir", res.Dir), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
 			status, ok := statuses[group.Name]
+			if p := pre.Groups[group.Name]; p != nil && p.Mode == workspace.PreModeExit {
+				status, ok = p.GraduationStatus(status), true
+			}
+			out := c.Root().Writer
+			jsonOutput := shared.OutputFlag(c) == shared.OutputJSON
 			if !ok {
 				logger.InfoContext(ctx, "no pending version bump found for group", slog.String("group", group.Name))
+				if jsonOutput {
+					return shared.WriteDocument(out, logger, &shared.Document{Groups: []shared.GroupDocument{{Name: group.Name}}})
+				}
 				return nil
 			}
 
-			nextVersion, err := workspace.GetNextVersion(ctx, workspace.ExecRunner{}, res.Dir, group, status.Level)
+			nextVersion, err := workspace.GetNextPreVersion(ctx, builtins.NewRunner(logger, res), res.Dir, group, status.Level, pre.Groups[group.Name])
 			if err != nil {
 				logger.ErrorContext(ctx, "failed to get next version", slog.String("group", group.Name), slog.String("error", err.Error()))
 				return cmd.Failed(err)
 			}
 
-			fmt.Println(nextVersion)
+			if jsonOutput {
+				return shared.WriteDocument(out, logger, &shared.Document{Groups: []shared.GroupDocument{
+					{Name: group.Name, Level: status.Level, NextVersion: nextVersion},
+				}})
+			}
+
+			fmt.Fprintln(out, nextVersion)
 
 			return nil
 		},
@@ -0,0 +1,219 @@
+package notes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+func NewCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "notes",
+		Usage: "Combine the release notes of several release groups into one markdown document",
+		Description: "Releases are read from a manifest written by `bumper commit --manifest`, or given as" +
+			" group@version arguments. A bare group name stands for the group's current version.",
+		ArgsUsage: "[group[@version]...]",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			&cli.StringFlag{
+				Name:      "manifest",
+				Usage:     "Read the releases from a release manifest written by `bumper commit --manifest`",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "title",
+				Usage: "The top-level heading of the document",
+				Value: "Release notes",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			var requested []string
+			if path := c.String("manifest"); path != "" {
+				manifest, err := workspace.LoadReleaseManifest(path)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to load release manifest", slog.String("file", path), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+				for _, record := range manifest.Releases {
+					requested = append(requested, record.Group+"@"+record.Version)
+				}
+			}
+			requested = append(requested, c.Args().Slice()...)
+
+			if len(requested) == 0 {
+				err := errors.New("no releases given: pass --manifest or group@version arguments")
+				logger.ErrorContext(ctx, err.Error())
+				return cmd.Failed(err)
+			}
+
+			runner := builtins.NewRunner(logger, res)
+			releases := make([]release, 0, len(requested))
+			for _, arg := range requested {
+				name, version := cutVersion(arg)
+				group, err := res.Group(ctx, logger, name)
+				if err != nil {
+					return err
+				}
+
+				if version == "" {
+					current, err := workspace.GetCurrentVersion(ctx, runner, res.Dir, group)
+					if err != nil {
+						logger.ErrorContext(ctx, "failed to get current version", slog.String("group", group.Name), slog.String("error", err.Error()))
+						return cmd.Failed(err)
+					}
+					version = current.String()
+				}
+
+				releases = append(releases, release{group: group, version: version})
+			}
+
+			return run(ctx, logger, runner, res.Dir, releases, c.String("title"), c.Root().Writer, shared.OutputFlag(c))
+		},
+	}
+}
+
+// release is a group version whose notes go into the document.
+type release struct {
+	group   workspace.ReleaseGroup
+	version string
+	notes   string
+}
+
+// title is the release's heading in the document.
+func (r release) title() string {
+	name := r.group.DisplayName
+	if name == "" {
+		name = r.group.Name
+	}
+
+	return name + " " + r.version
+}
+
+// cutVersion splits a group@version argument. Versions never contain "@", so
+// the last one separates the two.
+func cutVersion(arg string) (group string, version string) {
+	i := strings.LastIndex(arg, "@")
+	if i < 0 {
+		return arg, ""
+	}
+
+	return arg[:i], arg[i+1:]
+}
+
+// run reads every release's notes through its group's cat command and writes
+// the combined document. Under --output json the notes are listed per group
+// instead.
+func run(
+	ctx context.Context,
+	logger *slog.Logger,
+	runner workspace.Runner,
+	dir string,
+	releases []release,
+	title string,
+	stdout io.Writer,
+	output string,
+) error {
+	seen := make(map[string]struct{}, len(releases))
+	unique := make([]release, 0, len(releases))
+	for _, r := range releases {
+		key := r.group.Name + "@" + r.version
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		notes, err := workspace.GetReleaseNotes(ctx, runner, dir, r.group, r.version)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to read release notes", slog.String("group", r.group.Name), slog.String("version", r.version), slog.String("error", err.Error()))
+			return cmd.Failed(fmt.Errorf("release group %s: %w", r.group.Name, err))
+		}
+		if strings.TrimSpace(notes) == "" {
+			logger.WarnContext(ctx, "no release notes found", slog.String("group", r.group.Name), slog.String("version", r.version))
+		}
+
+		r.notes = notes
+		unique = append(unique, r)
+	}
+
+	if output == shared.OutputJSON {
+		doc := &shared.Document{Groups: make([]shared.GroupDocument, 0, len(unique))}
+		for _, r := range unique {
+			doc.Groups = append(doc.Groups, shared.GroupDocument{Name: r.group.Name, Version: r.version, Notes: r.notes})
+		}
+		return shared.WriteDocument(stdout, logger, doc)
+	}
+
+	_, err := io.WriteString(stdout, render(title, unique))
+	return err
+}
+
+// render builds the combined document: a title, a table of contents linking
+// to each release and one "## <name> <version>" section per release. A
+// heading on the first line of a group's notes is dropped in favour of the
+// section heading.
+func render(title string, releases []release) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	anchors := anchorSet{}
+	for _, r := range releases {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", r.title(), anchors.add(r.title()))
+	}
+
+	for _, r := range releases {
+		fmt.Fprintf(&b, "\n## %s\n\n", r.title())
+
+		body := strings.TrimSpace(r.notes)
+		if first, rest, _ := strings.Cut(body, "\n"); strings.HasPrefix(first, "#") {
+			body = strings.TrimSpace(rest)
+		}
+		if body == "" {
+			body = "_No release notes._"
+		}
+		b.WriteString(body)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+var anchorPunctuation = regexp.MustCompile(`[^\p{L}\p{N}\s_-]`)
+
+// anchorSet generates heading anchors the way GitHub does, suffixing repeated
+// anchors with "-1", "-2" and so on.
+type anchorSet map[string]int
+
+func (s anchorSet) add(heading string) string {
+	anchor := anchorPunctuation.ReplaceAllString(strings.ToLower(heading), "")
+	anchor = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '-'
+		}
+		return r
+	}, anchor)
+
+	n := s[anchor]
+	s[anchor] = n + 1
+	if n > 0 {
+		return fmt.Sprintf("%s-%d", anchor, n)
+	}
+
+	return anchor
+}
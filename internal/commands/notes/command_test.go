@@ -0,0 +1,121 @@
+package notes
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+func testGroup(name, displayName string) workspace.ReleaseGroup {
+	return workspace.ReleaseGroup{
+		Name:        name,
+		DisplayName: displayName,
+		CatCMD:      []string{"cat-" + name},
+	}
+}
+
+func TestCutVersion(t *testing.T) {
+	tests := []struct {
+		arg         string
+		wantGroup   string
+		wantVersion string
+	}{
+		{"api@1.2.0", "api", "1.2.0"},
+		{"api", "api", ""},
+		{"@scope/pkg@2.0.0-rc.1", "@scope/pkg", "2.0.0-rc.1"},
+	}
+	for _, tt := range tests {
+		group, version := cutVersion(tt.arg)
+		if group != tt.wantGroup || version != tt.wantVersion {
+			t.Errorf("cutVersion(%q) = %q, %q, want %q, %q", tt.arg, group, version, tt.wantGroup, tt.wantVersion)
+		}
+	}
+}
+
+func TestRunRendersCombinedDocument(t *testing.T) {
+	runner := &workspace.FakeRunner{Stdout: map[workspace.Verb]string{
+		workspace.VerbCat: "## Release 1.0.0\n\n### Minor Changes\n\n- abc1234: Added things\n",
+	}}
+	releases := []release{
+		{group: testGroup("api", "API"), version: "1.5.0"},
+		{group: testGroup("web", ""), version: "2.0.0"},
+		{group: testGroup("api", "API"), version: "1.5.0"},
+	}
+
+	var stdout bytes.Buffer
+	err := run(t.Context(), slog.New(slog.DiscardHandler), runner, t.TempDir(), releases, "Release notes", &stdout, shared.OutputText)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# Release notes
+
+- [API 1.5.0](#api-150)
+- [web 2.0.0](#web-200)
+
+## API 1.5.0
+
+### Minor Changes
+
+- abc1234: Added things
+
+## web 2.0.0
+
+### Minor Changes
+
+- abc1234: Added things
+`
+	if got := stdout.String(); got != want {
+		t.Errorf("document = %q, want %q", got, want)
+	}
+	if len(runner.Calls) != 2 {
+		t.Errorf("cat command ran %d times, want 2", len(runner.Calls))
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	runner := &workspace.FakeRunner{Stdout: map[workspace.Verb]string{workspace.VerbCat: "notes\n"}}
+	releases := []release{{group: testGroup("api", "API"), version: "1.5.0"}}
+
+	var stdout bytes.Buffer
+	err := run(t.Context(), slog.New(slog.DiscardHandler), runner, t.TempDir(), releases, "Release notes", &stdout, shared.OutputJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc shared.Document
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if len(doc.Groups) != 1 || doc.Groups[0].Name != "api" || doc.Groups[0].Version != "1.5.0" || doc.Groups[0].Notes != "notes\n" {
+		t.Errorf("groups = %+v", doc.Groups)
+	}
+}
+
+func TestRenderEmptyNotesAndRepeatedAnchors(t *testing.T) {
+	releases := []release{
+		{group: testGroup("a", "Pkg"), version: "1.0.0"},
+		{group: testGroup("b", "Pkg!"), version: "1.0.0"},
+	}
+
+	want := `# Notes
+
+- [Pkg 1.0.0](#pkg-100)
+- [Pkg! 1.0.0](#pkg-100-1)
+
+## Pkg 1.0.0
+
+_No release notes._
+
+## Pkg! 1.0.0
+
+_No release notes._
+`
+	if got := render("Notes", releases); got != want {
+		t.Errorf("render = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package pre
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+func NewCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "pre",
+		Usage: "Manage prerelease channels (alpha, beta, rc, ...) for release groups",
+		Commands: []*cli.Command{
+			newEnterCommand(logger),
+			newExitCommand(logger),
+		},
+	}
+}
+
+func newGroupFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "group",
+		Usage:   "The release group to change the prerelease mode of",
+		Sources: cli.EnvVars("BUMPER_GROUP"),
+	}
+}
+
+func newEnterCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "enter",
+		Usage: "Release subsequent commits of a group as prereleases on a channel",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			newGroupFlag(),
+			&cli.StringFlag{
+				Name:     "tag",
+				Usage:    "The prerelease channel tag, e.g. alpha, beta or rc",
+				Required: true,
+				Validator: func(s string) error {
+					return workspace.ValidatePreTag(s)
+				},
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			group, err := res.Group(ctx, logger, c.String("group"))
+			if err != nil {
+				return err
+			}
+
+			state, err := workspace.LoadPreState(res.Dir)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to load prerelease state", slog.String("dir", res.Dir), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			tag := c.String("tag")
+			if existing, ok := state.Groups[group.Name]; ok {
+				if existing.Mode == workspace.PreModeActive && existing.Tag == tag {
+					logger.InfoContext(ctx, "release group is already on this prerelease channel", slog.String("group", group.Name), slog.String("tag", tag))
+					return nil
+				}
+
+				// Switching channels (e.g. beta to rc) or cancelling a pending
+				// exit keeps the base version and accumulated entries, so the
+				// eventual graduation still covers every prerelease.
+				existing.Mode = workspace.PreModeActive
+				existing.Tag = tag
+			} else {
+				current, err := workspace.GetCurrentVersion(ctx, builtins.NewRunner(logger, res), res.Dir, group)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to get current version", slog.String("group", group.Name), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+
+				state.Groups[group.Name] = &workspace.PreReleaseGroup{
+					Mode: workspace.PreModeActive,
+					Tag:  tag,
+					Base: current.String(),
+				}
+			}
+
+			if err := workspace.SavePreState(res.Dir, state); err != nil {
+				logger.ErrorContext(ctx, "failed to save prerelease state", slog.String("dir", res.Dir), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			logger.InfoContext(ctx, "entered prerelease mode", slog.String("group", group.Name), slog.String("tag", tag))
+
+			return nil
+		},
+	}
+}
+
+func newExitCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "exit",
+		Usage: "Graduate a group from its prerelease channel on the next commit",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			newGroupFlag(),
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			group, err := res.Group(ctx, logger, c.String("group"))
+			if err != nil {
+				return err
+			}
+
+			state, err := workspace.LoadPreState(res.Dir)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to load prerelease state", slog.String("dir", res.Dir), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			existing, ok := state.Groups[group.Name]
+			switch {
+			case !ok:
+				logger.InfoContext(ctx, "release group is not in prerelease mode", slog.String("group", group.Name))
+				return nil
+			case existing.Level == workspace.BumpLevelNone:
+				// Nothing was released on the channel, so there is nothing to
+				// graduate: leave prerelease mode straight away.
+				delete(state.Groups, group.Name)
+				logger.InfoContext(ctx, "exited prerelease mode", slog.String("group", group.Name))
+			default:
+				existing.Mode = workspace.PreModeExit
+				logger.InfoContext(ctx, "release group will graduate on the next commit", slog.String("group", group.Name), slog.String("tag", existing.Tag))
+			}
+
+			if err := workspace.SavePreState(res.Dir, state); err != nil {
+				logger.ErrorContext(ctx, "failed to save prerelease state", slog.String("dir", res.Dir), slog.String("error", err.Error()))
+				return cmd.Failed(err)
+			}
+
+			return nil
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package shared
 
 import (
+	"fmt"
+
 	"github.com/urfave/cli/v3"
 )
 
@@ -14,3 +16,26 @@ func NewDirFlag() *cli.StringFlag {
 func DirFlag(c *cli.Command) string {
 	return c.String("dir")
 }
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+func NewOutputFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:  "output",
+		Usage: "Output format: text or json",
+		Value: OutputText,
+		Validator: func(s string) error {
+			if s != OutputText && s != OutputJSON {
+				return fmt.Errorf("unsupported output format %q: must be %q or %q", s, OutputText, OutputJSON)
+			}
+			return nil
+		},
+	}
+}
+
+func OutputFlag(c *cli.Command) string {
+	return c.String("output")
+}
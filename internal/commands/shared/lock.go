@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+func NewLockTimeoutFlag() *cli.DurationFlag {
+	return &cli.DurationFlag{
+		Name:    "lock-timeout",
+		Usage:   "How long to wait for another bumper process to release the workspace lock",
+		Value:   workspace.DefaultLockTimeout,
+		Sources: cli.EnvVars("BUMPER_LOCK_TIMEOUT"),
+	}
+}
+
+func LockTimeoutFlag(c *cli.Command) time.Duration {
+	return c.Duration("lock-timeout")
+}
+
+// Lock takes the lock on the workspace at dir for the rest of the command,
+// waiting up to timeout for another process to release it. Release it with
+// Unlock.
+func Lock(ctx context.Context, logger *slog.Logger, dir string, timeout time.Duration) (*workspace.Lock, error) {
+	lock, err := workspace.AcquireLock(ctx, dir, timeout)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to lock workspace", slog.String("file", workspace.LockFilename(dir)), slog.String("error", err.Error()))
+		return nil, cmd.Failed(err)
+	}
+
+	return lock, nil
+}
+
+// LockWorkingDir is Lock for commands that do not need a workspace, such as
+// the next:* builtins for version files. It locks the workspace containing
+// the working directory, or that of the workspace.Environ of ctx, if there is
+// one, and returns a nil lock otherwise.
+func LockWorkingDir(ctx context.Context, logger *slog.Logger, timeout time.Duration) (*workspace.Lock, error) {
+	dir, err := workspace.GetWd(workspace.EnvironFrom(ctx).Dir)
+	if err != nil {
+		return nil, nil
+	}
+
+	return Lock(ctx, logger, dir, timeout)
+}
+
+// Unlock releases a lock taken by Lock or LockWorkingDir. A nil lock is
+// ignored.
+func Unlock(ctx context.Context, logger *slog.Logger, lock *workspace.Lock) {
+	if lock == nil {
+		return
+	}
+
+	if err := lock.Release(); err != nil {
+		logger.WarnContext(ctx, "failed to release workspace lock", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/o11y"
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+// Document is what current, next, cat, commit, tag, check and infer print
+// under --output json.
+// The schema is stable: fields may be added, but existing fields keep their
+// names and meaning. It is documented in the CLI reference.
+type Document struct {
+	// DryRun is set when the document describes a release that was only
+	// previewed.
+	DryRun bool            `json:"dry_run,omitempty"`
+	Groups []GroupDocument `json:"groups"`
+	// BumpFiles are the bump files written by infer, relative to the
+	// workspace root.
+	BumpFiles []string `json:"bump_files,omitempty"`
+	// Warnings are the warnings logged while the command ran.
+	Warnings []string `json:"warnings"`
+}
+
+// GroupDocument describes one release group. Commands fill in the fields
+// they know about and omit the rest.
+type GroupDocument struct {
+	Name           string              `json:"name"`
+	CurrentVersion string              `json:"current_version,omitempty"`
+	NextVersion    string              `json:"next_version,omitempty"`
+	Level          workspace.BumpLevel `json:"level,omitempty"`
+	// Version is the version released by commit or whose notes cat printed.
+	Version string `json:"version,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+	// Tag is the git tag created by tag or commit --tag.
+	Tag string `json:"tag,omitempty"`
+	// Files are the changed files of a group that check found without a
+	// bump file.
+	Files []string `json:"files,omitempty"`
+}
+
+// WriteDocument prints doc as indented JSON with the warnings recorded by
+// logger.
+func WriteDocument(w io.Writer, logger *slog.Logger, doc *Document) error {
+	if doc.Groups == nil {
+		doc.Groups = []GroupDocument{}
+	}
+	doc.Warnings = o11y.RecordedWarnings(logger)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return cmd.Failed(fmt.Errorf("encode output document: %w", err))
+	}
+
+	return nil
+}
@@ -19,9 +19,20 @@ type Resolution struct {
 
 // Resolve is the single entry point from a CLI invocation to a resolved
 // workspace directory and validated config. rawDir is the --dir flag value;
-// empty means start the workspace search from the current directory.
+// empty means start the workspace search from the current directory. For a
+// builtin run in-process, the current directory and, when loaded, the config
+// come from the workspace.Environ of ctx.
 func Resolve(ctx context.Context, logger *slog.Logger, rawDir string) (*Resolution, error) {
-	dir, err := workspace.GetWd(rawDir)
+	env := workspace.EnvironFrom(ctx)
+	if rawDir == "" && env.Config != nil {
+		return &Resolution{Dir: env.Dir, Config: env.Config}, nil
+	}
+
+	start := rawDir
+	if start == "" {
+		start = env.Dir
+	}
+	dir, err := workspace.GetWd(env.Path(start))
 	if err != nil {
 		logger.ErrorContext(ctx, "workspace directory not found", slog.String("dir", rawDir), slog.String("error", err.Error()))
 		return nil, cmd.Failed(err)
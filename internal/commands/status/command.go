@@ -0,0 +1,205 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/o11y"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+// GroupStatus is the pending release of one release group. Fields are part of
+// the --output json document.
+type GroupStatus struct {
+	Name           string              `json:"name"`
+	CurrentVersion string              `json:"current_version"`
+	Level          workspace.BumpLevel `json:"level"`
+	NextVersion    string              `json:"next_version,omitempty"`
+	Prerelease     string              `json:"prerelease,omitempty"`
+	Bumps          []BumpStatus        `json:"bumps"`
+	// Dependencies names the released dependencies that imply a bump.
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// BumpStatus is one bump file contributing to a group's pending release.
+type BumpStatus struct {
+	File   string `json:"file"`
+	Level  string `json:"level"`
+	Commit string `json:"commit,omitempty"`
+}
+
+type Report struct {
+	Groups []GroupStatus `json:"groups"`
+	// Warnings are the warnings logged while building the report.
+	Warnings []string `json:"warnings"`
+}
+
+func NewCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Summarize the pending version bumps of every release group",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			report, err := buildReport(ctx, logger, builtins.NewRunner(logger, res), workspace.NewGitProvenance(logger, res.Dir), res.Dir, res.Config)
+			if err != nil {
+				return err
+			}
+
+			report.Warnings = o11y.RecordedWarnings(logger)
+
+			return printReport(c.Root().Writer, report, shared.OutputFlag(c))
+		},
+	}
+}
+
+// buildReport computes what `bumper commit` would release for every group,
+// in config order, without running any command that changes the workspace.
+func buildReport(
+	ctx context.Context,
+	logger *slog.Logger,
+	runner workspace.Runner,
+	provenance workspace.Provenance,
+	dir string,
+	cfg *workspace.Config,
+) (*Report, error) {
+	bumps, err := workspace.GatherBumps(ctx, logger, dir, provenance)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to gather pending bumps", slog.String("dir", dir), slog.String("error", err.Error()))
+		return nil, cmd.Failed(err)
+	}
+
+	statuses := workspace.SquashBumps(ctx, logger, bumps, cfg)
+
+	pre, err := workspace.LoadPreState(dir)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to load prerelease state", slog.String("dir", dir), slog.String("error", err.Error()))
+		return nil, cmd.Failed(err)
+	}
+
+	fixedVersions, err := workspace.FixedVersions(ctx, runner, dir, cfg, statuses, pre, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to compute fixed set versions", slog.String("error", err.Error()))
+		return nil, cmd.Failed(err)
+	}
+
+	report := &Report{Groups: make([]GroupStatus, 0, len(cfg.Groups))}
+	for _, group := range cfg.Groups {
+		current, err := workspace.GetCurrentVersion(ctx, runner, dir, group)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to get current version", slog.String("group", group.Name), slog.String("error", err.Error()))
+			return nil, cmd.Failed(fmt.Errorf("release group %s: %w", group.Name, err))
+		}
+
+		gs := GroupStatus{
+			Name:           group.Name,
+			CurrentVersion: current.String(),
+			Bumps:          []BumpStatus{},
+		}
+
+		status := statuses[group.Name]
+		p := pre.Groups[group.Name]
+		if p != nil {
+			gs.Prerelease = p.Tag
+			if p.Mode == workspace.PreModeExit {
+				status = p.GraduationStatus(status)
+			}
+		}
+
+		if status != nil && status.Level != workspace.BumpLevelNone {
+			gs.Level = status.Level
+			for _, update := range status.Dependencies {
+				gs.Dependencies = append(gs.Dependencies, update.Group)
+			}
+			if version, ok := fixedVersions[group.Name]; ok {
+				gs.NextVersion = version
+			} else {
+				gs.NextVersion, err = workspace.ComputeNextVersion(current, status.Level, p)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to get next version", slog.String("group", group.Name), slog.String("error", err.Error()))
+					return nil, cmd.Failed(fmt.Errorf("release group %s: %w", group.Name, err))
+				}
+			}
+		}
+
+		for _, bump := range bumps {
+			level, ok := bump.Levels[group.Name]
+			if !ok {
+				continue
+			}
+
+			bs := BumpStatus{File: bump.File, Level: level}
+			if rel, err := filepath.Rel(dir, bump.File); err == nil {
+				bs.File = rel
+			}
+			if bump.Commit != nil {
+				bs.Commit = bump.Commit.SHA
+			}
+			gs.Bumps = append(gs.Bumps, bs)
+		}
+		slices.SortStableFunc(gs.Bumps, func(a, b BumpStatus) int {
+			return strings.Compare(a.File, b.File)
+		})
+
+		report.Groups = append(report.Groups, gs)
+	}
+
+	return report, nil
+}
+
+func printReport(w io.Writer, report *Report, output string) error {
+	if output == shared.OutputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return cmd.Failed(fmt.Errorf("encode status report: %w", err))
+		}
+		return nil
+	}
+
+	for _, gs := range report.Groups {
+		fmt.Fprintln(w, gs.Name)
+		fmt.Fprintf(w, "  current: %s\n", gs.CurrentVersion)
+		if gs.Prerelease != "" {
+			fmt.Fprintf(w, "  channel: %s\n", gs.Prerelease)
+		}
+		if gs.NextVersion == "" {
+			fmt.Fprintln(w, "  no pending bumps")
+			continue
+		}
+
+		fmt.Fprintf(w, "  level:   %s\n", gs.Level)
+		fmt.Fprintf(w, "  next:    %s\n", gs.NextVersion)
+		if len(gs.Dependencies) > 0 {
+			fmt.Fprintf(w, "  updated dependencies: %s\n", strings.Join(gs.Dependencies, ", "))
+		}
+		if len(gs.Bumps) > 0 {
+			fmt.Fprintln(w, "  bumps:")
+		}
+		for _, bump := range gs.Bumps {
+			commit := "uncommitted"
+			if bump.Commit != "" {
+				commit = bump.Commit[:min(7, len(bump.Commit))]
+			}
+			fmt.Fprintf(w, "    - %s (%s, %s)\n", bump.File, bump.Level, commit)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/disintegrator/bumper/internal/workspace"
+)
+
+func testGroup(name string) workspace.ReleaseGroup {
+	return workspace.ReleaseGroup{
+		Name:         name,
+		ChangelogCMD: []string{"true"},
+		CatCMD:       []string{"true"},
+		CurrentCMD:   []string{"true"},
+		NextCMD:      []string{"true"},
+	}
+}
+
+func writeBump(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := workspace.BumpFilename(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write bump file: %v", err)
+	}
+
+	return path
+}
+
+func TestBuildReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(workspace.Dir(dir), 0o755); err != nil {
+		t.Fatalf("create .bumper dir: %v", err)
+	}
+	feature := writeBump(t, dir, "feature", "---\napi: minor\n---\n\nNew endpoint\n")
+	writeBump(t, dir, "fix", "---\napi: patch\nweb: patch\n---\n\nShared fix\n")
+
+	cfg := &workspace.Config{Groups: []workspace.ReleaseGroup{
+		testGroup("api"), testGroup("web"), testGroup("docs"),
+	}}
+	runner := &workspace.FakeRunner{Stdout: map[workspace.Verb]string{workspace.VerbCurrent: "1.0.0\n"}}
+	prov := &workspace.FakeProvenance{Commits: map[string]workspace.Commit{
+		feature: {SHA: "abc1234def5678", When: time.Unix(1700000000, 0)},
+	}}
+	logger := slog.New(slog.DiscardHandler)
+
+	report, err := buildReport(t.Context(), logger, runner, prov, dir, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Report{Groups: []GroupStatus{
+		{
+			Name:           "api",
+			CurrentVersion: "1.0.0",
+			Level:          workspace.BumpLevelMinor,
+			NextVersion:    "1.1.0",
+			Bumps: []BumpStatus{
+				{File: ".bumper/bump-feature.md", Level: "minor", Commit: "abc1234def5678"},
+				{File: ".bumper/bump-fix.md", Level: "patch"},
+			},
+		},
+		{
+			Name:           "web",
+			CurrentVersion: "1.0.0",
+			Level:          workspace.BumpLevelPatch,
+			NextVersion:    "1.0.1",
+			Bumps:          []BumpStatus{{File: ".bumper/bump-fix.md", Level: "patch"}},
+		},
+		{
+			Name:           "docs",
+			CurrentVersion: "1.0.0",
+			Bumps:          []BumpStatus{},
+		},
+	}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %#v, want %#v", report, want)
+	}
+
+	// Status never runs commands that change the workspace.
+	for _, call := range runner.Calls {
+		if call.Invocation.Verb != workspace.VerbCurrent {
+			t.Errorf("unexpected %s invocation", call.Invocation.Verb)
+		}
+	}
+	if len(runner.Calls) != len(cfg.Groups) {
+		t.Errorf("calls = %d, want one current invocation per group", len(runner.Calls))
+	}
+}
+
+func TestPrintReportJSON(t *testing.T) {
+	report := &Report{Groups: []GroupStatus{
+		{
+			Name:           "api",
+			CurrentVersion: "1.0.0",
+			Level:          workspace.BumpLevelMinor,
+			NextVersion:    "1.1.0",
+			Bumps:          []BumpStatus{{File: ".bumper/bump-feature.md", Level: "minor"}},
+		},
+	}}
+
+	var out bytes.Buffer
+	if err := printReport(&out, report, "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	group := decoded["groups"].([]any)[0].(map[string]any)
+	if group["level"] != "minor" {
+		t.Errorf("level = %#v, want %q", group["level"], "minor")
+	}
+	if group["next_version"] != "1.1.0" {
+		t.Errorf("next_version = %#v, want %q", group["next_version"], "1.1.0")
+	}
+}
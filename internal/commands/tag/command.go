@@ -0,0 +1,102 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/disintegrator/bumper/internal/cmd"
+	"github.com/disintegrator/bumper/internal/commands/builtins"
+	"github.com/disintegrator/bumper/internal/commands/shared"
+	"github.com/disintegrator/bumper/internal/workspace"
+	"github.com/urfave/cli/v3"
+)
+
+func NewCommand(logger *slog.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "tag",
+		Usage: "Create annotated git tags for the current versions of release groups",
+		Flags: []cli.Flag{
+			shared.NewDirFlag(),
+			&cli.StringSliceFlag{
+				Name:  "group",
+				Usage: "The release groups to tag (defaults to every group with a tag_format)",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			res, err := shared.Resolve(ctx, logger, shared.DirFlag(c))
+			if err != nil {
+				return err
+			}
+
+			var groups []workspace.ReleaseGroup
+			if names := c.StringSlice("group"); len(names) > 0 {
+				for _, name := range names {
+					group, err := res.Group(ctx, logger, name)
+					if err != nil {
+						return err
+					}
+					if group.TagFormat == "" {
+						err := fmt.Errorf("%s: release group has no tag_format configured", name)
+						logger.ErrorContext(ctx, err.Error())
+						return cmd.Failed(err)
+					}
+					groups = append(groups, group)
+				}
+			} else {
+				for _, group := range res.Config.Groups {
+					if group.TagFormat != "" {
+						groups = append(groups, group)
+					}
+				}
+			}
+
+			out := c.Root().Writer
+			jsonOutput := shared.OutputFlag(c) == shared.OutputJSON
+			doc := &shared.Document{}
+
+			if len(groups) == 0 {
+				logger.InfoContext(ctx, "no release groups have a tag_format configured")
+				if jsonOutput {
+					return shared.WriteDocument(out, logger, doc)
+				}
+				return nil
+			}
+
+			runner := builtins.NewRunner(logger, res)
+			for _, group := range groups {
+				version, err := workspace.GetCurrentVersion(ctx, runner, res.Dir, group)
+				if err != nil {
+					logger.ErrorContext(ctx, "failed to get current version", slog.String("group", group.Name), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+
+				name, err := workspace.TagRelease(ctx, runner, res.Dir, group, version.String())
+				switch {
+				case errors.Is(err, workspace.ErrTagExists):
+					// The current version was tagged before; existing tags are
+					// never moved.
+					logger.WarnContext(ctx, "tag already exists, not overwriting", slog.String("group", group.Name), slog.String("tag", group.TagName(version.String())))
+					continue
+				case err != nil:
+					logger.ErrorContext(ctx, "failed to create tag", slog.String("group", group.Name), slog.String("error", err.Error()))
+					return cmd.Failed(err)
+				}
+
+				logger.InfoContext(ctx, "created tag", slog.String("group", group.Name), slog.String("tag", name))
+				if jsonOutput {
+					doc.Groups = append(doc.Groups, shared.GroupDocument{Name: group.Name, Version: version.String(), Tag: name})
+					continue
+				}
+				fmt.Fprintln(out, name)
+			}
+
+			if jsonOutput {
+				return shared.WriteDocument(out, logger, doc)
+			}
+
+			return nil
+		},
+	}
+}
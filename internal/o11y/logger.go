@@ -1,13 +1,46 @@
 package o11y
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
 
 	"github.com/charmbracelet/log"
 )
 
+// NewLogger returns the CLI logger. It writes to stderr and records warnings
+// for RecordedWarnings.
 func NewLogger() *slog.Logger {
 	handler := log.New(os.Stderr)
-	return slog.New(handler)
+	return slog.New(NewWarningRecorder(handler))
+}
+
+// WithOutput returns a logger like logger that writes to w instead, such as
+// the buffered stderr of a release group run alongside others. It logs at
+// the lowest level logger logs at, and records warnings along with logger
+// when it is a WarningRecorder. A logger discarding every record is
+// returned as is.
+func WithOutput(logger *slog.Logger, w io.Writer) *slog.Logger {
+	recorder, recording := logger.Handler().(*WarningRecorder)
+	inner := logger.Handler()
+	if recording {
+		inner = recorder.Handler
+	}
+
+	levels := []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+	i := 0
+	for i < len(levels) && !inner.Enabled(context.Background(), levels[i]) {
+		i++
+	}
+	if i == len(levels) {
+		return logger
+	}
+
+	handler := log.NewWithOptions(w, log.Options{Level: log.Level(levels[i])})
+	if !recording {
+		return slog.New(handler)
+	}
+
+	return slog.New(&WarningRecorder{Handler: handler, mu: recorder.mu, warnings: recorder.warnings})
 }
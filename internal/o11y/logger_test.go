@@ -0,0 +1,29 @@
+package o11y
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithOutput(t *testing.T) {
+	logger := slog.New(NewWarningRecorder(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	var out bytes.Buffer
+	WithOutput(logger, &out).Warn("version file does not exist")
+
+	if !strings.Contains(out.String(), "version file does not exist") {
+		t.Errorf("output = %q, want the warning", out.String())
+	}
+	want := []string{"version file does not exist"}
+	if got := RecordedWarnings(logger); !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %#v, want %#v", got, want)
+	}
+
+	discard := slog.New(slog.DiscardHandler)
+	if got := WithOutput(discard, &out); got != discard {
+		t.Errorf("WithOutput returned a new logger for a discarding one")
+	}
+}
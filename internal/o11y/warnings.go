@@ -0,0 +1,85 @@
+package o11y
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// WarningRecorder is a slog.Handler that passes records through to Handler
+// and keeps the text of every warning so commands can report them in
+// machine-readable output.
+type WarningRecorder struct {
+	slog.Handler
+
+	mu       *sync.Mutex
+	warnings *[]string
+	attrs    []slog.Attr
+}
+
+func NewWarningRecorder(handler slog.Handler) *WarningRecorder {
+	return &WarningRecorder{Handler: handler, mu: &sync.Mutex{}, warnings: &[]string{}}
+}
+
+// Enabled always accepts warnings so they are recorded even when Handler
+// filters them out.
+func (w *WarningRecorder) Enabled(ctx context.Context, level slog.Level) bool {
+	return level == slog.LevelWarn || w.Handler.Enabled(ctx, level)
+}
+
+func (w *WarningRecorder) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelWarn {
+		var b strings.Builder
+		b.WriteString(r.Message)
+		write := func(a slog.Attr) bool {
+			b.WriteString(" " + a.Key + "=" + a.Value.String())
+			return true
+		}
+		for _, a := range w.attrs {
+			write(a)
+		}
+		r.Attrs(write)
+
+		w.mu.Lock()
+		*w.warnings = append(*w.warnings, b.String())
+		w.mu.Unlock()
+	}
+
+	if !w.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+
+	return w.Handler.Handle(ctx, r)
+}
+
+func (w *WarningRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &WarningRecorder{
+		Handler:  w.Handler.WithAttrs(attrs),
+		mu:       w.mu,
+		warnings: w.warnings,
+		attrs:    append(w.attrs[:len(w.attrs):len(w.attrs)], attrs...),
+	}
+}
+
+func (w *WarningRecorder) WithGroup(name string) slog.Handler {
+	return &WarningRecorder{Handler: w.Handler.WithGroup(name), mu: w.mu, warnings: w.warnings, attrs: w.attrs}
+}
+
+// Warnings returns the warnings recorded so far, in order.
+func (w *WarningRecorder) Warnings() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string{}, *w.warnings...)
+}
+
+// RecordedWarnings returns the warnings recorded by logger's handler, or an
+// empty list when it is not a WarningRecorder.
+func RecordedWarnings(logger *slog.Logger) []string {
+	if w, ok := logger.Handler().(*WarningRecorder); ok {
+		return w.Warnings()
+	}
+
+	return []string{}
+}
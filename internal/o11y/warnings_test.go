@@ -0,0 +1,24 @@
+package o11y
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestWarningRecorder(t *testing.T) {
+	logger := slog.New(NewWarningRecorder(slog.DiscardHandler))
+
+	logger.Info("not recorded")
+	logger.With(slog.String("group", "api")).Warn("skipping bump", slog.String("file", "bump-a.md"))
+	logger.Error("not recorded either")
+
+	want := []string{"skipping bump group=api file=bump-a.md"}
+	if got := RecordedWarnings(logger); !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %#v, want %#v", got, want)
+	}
+
+	if got := RecordedWarnings(slog.New(slog.DiscardHandler)); len(got) != 0 {
+		t.Errorf("warnings = %#v, want none without a recorder", got)
+	}
+}
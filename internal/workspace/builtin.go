@@ -0,0 +1,181 @@
+package workspace
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// BuiltinFunc runs one of bumper's builtins in this process, as
+// `bumper builtins <args>` would in the working directory and environment
+// described by env. args starts with the builtin's name.
+type BuiltinFunc func(ctx context.Context, env Environ, args []string, stdout io.Writer, stderr io.Writer) error
+
+// Environ is the working directory and environment of a builtin run in this
+// process. Builtins read them from here rather than from the process, so
+// several can run at once. The zero Environ is the process's own.
+type Environ struct {
+	// Dir is the working directory. Empty means the process's.
+	Dir string
+	// Env holds KEY=VALUE pairs added to the process environment, later
+	// entries taking precedence.
+	Env []string
+	// Config is the already loaded config of the workspace at Dir, if any.
+	Config *Config
+}
+
+type environKey struct{}
+
+// WithEnviron returns a context carrying env for the builtin run in it.
+func WithEnviron(ctx context.Context, env Environ) context.Context {
+	return context.WithValue(ctx, environKey{}, env)
+}
+
+// EnvironFrom returns the Environ carried by ctx, or the zero Environ.
+func EnvironFrom(ctx context.Context) Environ {
+	env, _ := ctx.Value(environKey{}).(Environ)
+	return env
+}
+
+// LookupEnv is os.LookupEnv for the environment described by e.
+func (e Environ) LookupEnv(key string) (string, bool) {
+	for _, entry := range slices.Backward(e.Env) {
+		if k, v, _ := strings.Cut(entry, "="); k == key {
+			return v, true
+		}
+	}
+
+	return os.LookupEnv(key)
+}
+
+// Path resolves a relative path against the working directory of e.
+func (e Environ) Path(path string) string {
+	if e.Dir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(e.Dir, path)
+}
+
+// BuiltinArgs returns the arguments after `bumper builtins` (or its alias
+// `bumper b`) when argv runs one of bumper's builtins: argv[0] is a program
+// named bumper or the running executable. Commands run through a wrapper,
+// such as `mise run -q bumper builtins ...`, do not match, since the wrapper
+// may run them elsewhere. The first argument is the builtin's name, e.g.
+// "next:default".
+func BuiltinArgs(argv []string) ([]string, bool) {
+	if len(argv) < 3 || !isBumper(argv[0]) || (argv[1] != "builtins" && argv[1] != "b") {
+		return nil, false
+	}
+
+	return argv[2:], true
+}
+
+// isBumper reports whether the program name runs bumper: its base name is
+// bumper, or it is the absolute path of the running executable.
+func isBumper(name string) bool {
+	if strings.TrimSuffix(filepath.Base(name), ".exe") == "bumper" {
+		return true
+	}
+	if !filepath.IsAbs(name) {
+		return false
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	exeInfo, err := os.Stat(exe)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(exeInfo, info)
+}
+
+// WrittenFiles lists the files in the workspace at base that the group's
+// next_cmd and changelog_cmd write: its changelog, plus the files named by
+// the builtins' --path and --package flags and .bumper/versions.toml for
+// next:default. known is false when either command is not one of these
+// builtins, so the files it writes cannot be listed. Groups writing the same
+// file, or a file that is not known, must not be released concurrently.
+func (g ReleaseGroup) WrittenFiles(base string) (files []string, known bool) {
+	files = []string{g.ChangelogFilename(base)}
+	known = true
+
+	if len(g.ChangelogCMD) > 0 {
+		args, ok := BuiltinArgs(g.ChangelogCMD)
+		switch {
+		case ok && strings.HasPrefix(args[0], "amendlog:"):
+			if paths := flagValues(args[1:], "path"); len(paths) > 0 {
+				files = paths
+			}
+		default:
+			known = false
+		}
+	}
+
+	if len(g.NextCMD) > 0 {
+		args, ok := BuiltinArgs(g.NextCMD)
+		switch {
+		case ok && args[0] == "next:default":
+			files = append(files, VersionFilename(base))
+		case ok && strings.HasPrefix(args[0], "next:"):
+			files = append(files, flagValues(args[1:], "path", "package")...)
+		default:
+			known = false
+		}
+	}
+
+	for i, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(base, file)
+		}
+		files[i] = filepath.Clean(file)
+	}
+	slices.Sort(files)
+
+	return slices.Compact(files), known
+}
+
+// NpmPackages lists the package.json files that the group's next_cmd and
+// current_cmd read and write with the npm builtins, as given in their
+// --package flags, i.e. relative to the workspace root unless absolute.
+func (g ReleaseGroup) NpmPackages() []string {
+	var files []string
+	for _, argv := range [][]string{g.NextCMD, g.CurrentCMD} {
+		if args, ok := BuiltinArgs(argv); ok && (args[0] == "next:npm" || args[0] == "current:npm") {
+			files = append(files, flagValues(args[1:], "package")...)
+		}
+	}
+
+	slices.Sort(files)
+	return slices.Compact(files)
+}
+
+// flagValues returns the values of the named flags in args, given either as
+// --name value or --name=value.
+func flagValues(args []string, names ...string) []string {
+	var values []string
+	for i := 0; i < len(args); i++ {
+		for _, name := range names {
+			flag := "--" + name
+			switch arg := args[i]; {
+			case arg == flag && i+1 < len(args):
+				values = append(values, args[i+1])
+				i++
+			case strings.HasPrefix(arg, flag+"="):
+				values = append(values, strings.TrimPrefix(arg, flag+"="))
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,131 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinArgs(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("find test executable: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		argv   []string
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "direct",
+			argv:   []string{"bumper", "builtins", "next:default"},
+			want:   []string{"next:default"},
+			wantOK: true,
+		},
+		{
+			name:   "alias and path",
+			argv:   []string{"/usr/local/bin/bumper", "b", "next:file", "--path", "VERSION"},
+			want:   []string{"next:file", "--path", "VERSION"},
+			wantOK: true,
+		},
+		{
+			name:   "running executable",
+			argv:   []string{executable, "builtins", "cat:default"},
+			want:   []string{"cat:default"},
+			wantOK: true,
+		},
+		{name: "mise wrapper", argv: []string{"mise", "run", "-q", "bumper", "builtins", "amendlog:default"}},
+		{name: "docker wrapper", argv: []string{"docker", "run", "img", "bumper", "b", "next:default"}},
+		{name: "env wrapper", argv: []string{"env", "X=1", "bumper", "builtins", "next:default"}},
+		{name: "no builtin name", argv: []string{"bumper", "builtins"}},
+		{name: "other bumper command", argv: []string{"bumper", "commit"}},
+		{name: "other command", argv: []string{"./scripts/next.sh", "builtins"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := BuiltinArgs(tt.argv)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuiltinArgs(%q) = %q, %v, want %q, %v", tt.argv, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWrittenFiles(t *testing.T) {
+	base := t.TempDir()
+
+	tests := []struct {
+		name      string
+		group     ReleaseGroup
+		want      []string
+		wantKnown bool
+	}{
+		{
+			name:  "custom next_cmd",
+			group: ReleaseGroup{Name: "a", NextCMD: []string{"./next.sh"}},
+			want:  []string{"CHANGELOG.md"},
+		},
+		{
+			name: "custom changelog_cmd",
+			group: ReleaseGroup{
+				Name:         "a",
+				NextCMD:      []string{"bumper", "builtins", "next:file", "--path", "VERSION"},
+				ChangelogCMD: []string{"./changelog.sh"},
+			},
+			want: []string{"CHANGELOG.md", "VERSION"},
+		},
+		{
+			name: "next:default",
+			group: ReleaseGroup{
+				Name:          "a",
+				ChangelogPath: "a/CHANGELOG.md",
+				NextCMD:       []string{"bumper", "builtins", "next:default"},
+			},
+			want:      []string{".bumper/versions.toml", "a/CHANGELOG.md"},
+			wantKnown: true,
+		},
+		{
+			name: "builtin paths",
+			group: ReleaseGroup{
+				Name:         "a",
+				NextCMD:      []string{"bumper", "builtins", "next:npm", "--package", "a/package.json", "--package=b/package.json"},
+				ChangelogCMD: []string{"bumper", "builtins", "amendlog:default", "--path", "docs/CHANGES.md"},
+			},
+			want:      []string{"a/package.json", "b/package.json", "docs/CHANGES.md"},
+			wantKnown: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([]string, 0, len(tt.want))
+			for _, file := range tt.want {
+				want = append(want, filepath.Join(base, filepath.FromSlash(file)))
+			}
+			got, known := tt.group.WrittenFiles(base)
+			if !reflect.DeepEqual(got, want) || known != tt.wantKnown {
+				t.Errorf("WrittenFiles() = %q, %v, want %q, %v", got, known, want, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestNpmPackages(t *testing.T) {
+	group := ReleaseGroup{
+		Name:       "sdk",
+		CurrentCMD: []string{"bumper", "builtins", "current:npm", "--package", "packages/sdk/package.json"},
+		NextCMD:    []string{"bumper", "builtins", "next:npm", "--package=packages/sdk/package.json", "--package", "packages/sdk/jsr.json"},
+	}
+	want := []string{"packages/sdk/jsr.json", "packages/sdk/package.json"}
+	if got := group.NpmPackages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NpmPackages() = %q, want %q", got, want)
+	}
+
+	if got := (ReleaseGroup{NextCMD: []string{"./next.sh", "--package", "package.json"}}).NpmPackages(); len(got) != 0 {
+		t.Errorf("NpmPackages() = %q, want none for a custom command", got)
+	}
+}
@@ -11,15 +11,40 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/disintegrator/bumper/internal/changelog"
 	"github.com/goccy/go-yaml"
 )
 
 type LogEntry struct {
-	Timestamp int64
-	Commit    string
-	Content   string
+	// Timestamp is the commit date of the bump in Unix nanoseconds, or 0 when
+	// unknown.
+	Timestamp int64 `toml:"timestamp"`
+	// Commit is the abbreviated hash of the commit that added the bump.
+	Commit string `toml:"commit"`
+	// SHA is the full hash behind Commit.
+	SHA     string `toml:"sha,omitempty"`
+	Content string `toml:"content"`
+	// Message is Content without the commit prefix.
+	Message     string `toml:"message,omitempty"`
+	AuthorName  string `toml:"author_name,omitempty"`
+	AuthorEmail string `toml:"author_email,omitempty"`
+	// File is the name of the bump file the entry came from.
+	File string `toml:"file,omitempty"`
+	// Category is the entry's Keep a Changelog category, if the bump file
+	// sets one, see changelog.CategoryNames.
+	Category string `toml:"category,omitempty"`
+}
+
+// Date returns the commit date of the bump, or the zero time when unknown.
+func (e LogEntry) Date() time.Time {
+	if e.Timestamp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, e.Timestamp)
 }
 
 type ReleaseGroupStatus struct {
@@ -27,6 +52,79 @@ type ReleaseGroupStatus struct {
 	MajorLogs []LogEntry
 	MinorLogs []LogEntry
 	PatchLogs []LogEntry
+	// Dependencies lists the released dependencies that imply a bump of
+	// this group, in release order.
+	Dependencies []DependencyUpdate
+}
+
+// DependencyUpdate records that a release group is bumped because a group it
+// depends on is released.
+type DependencyUpdate struct {
+	Group string
+	Level BumpLevel
+}
+
+func newReleaseGroupStatus() *ReleaseGroupStatus {
+	return &ReleaseGroupStatus{
+		Level:     BumpLevelNone,
+		MajorLogs: []LogEntry{},
+		MinorLogs: []LogEntry{},
+		PatchLogs: []LogEntry{},
+	}
+}
+
+// CommitSHAs lists the full hashes of the commits that contributed entries,
+// oldest first and without duplicates.
+func (s *ReleaseGroupStatus) CommitSHAs() []string {
+	entries := slices.Concat(s.MajorLogs, s.MinorLogs, s.PatchLogs)
+	slices.SortStableFunc(entries, func(a, b LogEntry) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+
+	shas := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.SHA != "" && !slices.Contains(shas, entry.SHA) {
+			shas = append(shas, entry.SHA)
+		}
+	}
+
+	return shas
+}
+
+// AddDependencyEntries appends a changelog entry for every dependency update,
+// at the level the update implied. versions maps release group names to the
+// versions just released; updates whose dependency has no version yet are
+// skipped.
+func (s *ReleaseGroupStatus) AddDependencyEntries(versions map[string]string) {
+	for _, update := range s.Dependencies {
+		version, ok := versions[update.Group]
+		if !ok {
+			continue
+		}
+
+		entry := LogEntry{Content: fmt.Sprintf("Updated dependency %s to %s", update.Group, version)}
+		switch update.Level {
+		case BumpLevelMajor:
+			s.MajorLogs = append(s.MajorLogs, entry)
+		case BumpLevelMinor:
+			s.MinorLogs = append(s.MinorLogs, entry)
+		case BumpLevelPatch:
+			s.PatchLogs = append(s.PatchLogs, entry)
+		}
+	}
+}
+
+// DependencyVersions maps the dependencies behind the group's bump to the
+// versions just released for them.
+func (s *ReleaseGroupStatus) DependencyVersions(versions map[string]string) map[string]string {
+	result := make(map[string]string, len(s.Dependencies))
+	for _, update := range s.Dependencies {
+		if version, ok := versions[update.Group]; ok {
+			result[update.Group] = version
+		}
+	}
+
+	return result
 }
 
 // ParsedBump is one bump file's parsed content plus its provenance: the
@@ -37,6 +135,27 @@ type ParsedBump struct {
 	Levels  map[string]string
 	Message string
 	Commit  *Commit
+	// Categories maps the release groups whose entry has a Keep a Changelog
+	// category to that category.
+	Categories map[string]string
+}
+
+// bumpTarget is a release group's value in a bump file's front matter:
+// either its level, as in "api: major", or a mapping of the level and the
+// Keep a Changelog category of the entry, as in
+// "api: {level: major, category: removed}".
+type bumpTarget struct {
+	Level    string `yaml:"level"`
+	Category string `yaml:"category"`
+}
+
+func (t *bumpTarget) UnmarshalYAML(unmarshal func(any) error) error {
+	if err := unmarshal(&t.Level); err == nil {
+		return nil
+	}
+
+	type plain bumpTarget
+	return unmarshal((*plain)(t))
 }
 
 // CollectBumps composes GatherBumps and SquashBumps: it reads the pending
@@ -70,20 +189,12 @@ func GatherBumps(ctx context.Context, logger *slog.Logger, dir string, provenanc
 
 	bumps := make([]ParsedBump, 0, len(matches))
 	for _, match := range matches {
-		content, err := os.ReadFile(match)
+		bump, err := ReadBumpFile(match)
 		if err != nil {
 			logger.ErrorContext(ctx, "failed to read bump file", slog.String("file", match), slog.String("error", err.Error()))
 			return nil, fmt.Errorf("process bump files: %w", err)
 		}
 
-		levels := make(map[string]string)
-		message, err := extractFrontMatter(string(content), &levels)
-		if err != nil {
-			logger.ErrorContext(ctx, "failed to extract front matter", slog.String("file", match), slog.String("error", err.Error()))
-			return nil, fmt.Errorf("process bump files: %w", err)
-		}
-
-		bump := ParsedBump{File: match, Levels: levels, Message: message}
 		if commit, ok := gitInfo[match]; ok {
 			bump.Commit = &commit
 		}
@@ -94,19 +205,55 @@ func GatherBumps(ctx context.Context, logger *slog.Logger, dir string, provenanc
 	return bumps, nil
 }
 
+// ReadBumpFile parses the front matter levels and message of the bump file at
+// path. The returned bump has no commit.
+func ReadBumpFile(path string) (ParsedBump, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ParsedBump{}, err
+	}
+
+	targets := make(map[string]bumpTarget)
+	message, err := extractFrontMatter(string(content), &targets)
+	if err != nil {
+		return ParsedBump{}, fmt.Errorf("extract front matter: %w", err)
+	}
+
+	bump := ParsedBump{File: path, Levels: make(map[string]string, len(targets)), Message: message}
+	for groupName, target := range targets {
+		bump.Levels[groupName] = target.Level
+		if target.Category != "" {
+			if bump.Categories == nil {
+				bump.Categories = make(map[string]string)
+			}
+			bump.Categories[groupName] = target.Category
+		}
+	}
+
+	return bump, nil
+}
+
 // SquashBumps reduces parsed bump files to per-group release status: the
 // highest bump level wins per group and changelog entries are ordered by
-// commit timestamp. Bumps for groups absent from cfg and entries with unknown
-// levels are skipped with a warning. It touches neither disk nor git.
+// commit timestamp. Bumps then cascade to dependent groups according to
+// their dependency policies and are shared across fixed and linked sets.
+// Bumps for groups absent from cfg and entries with unknown levels are
+// skipped with a warning. It touches neither disk nor git.
 func SquashBumps(ctx context.Context, logger *slog.Logger, bumps []ParsedBump, cfg *Config) map[string]*ReleaseGroupStatus {
 	statuses := make(map[string]*ReleaseGroupStatus)
 
 	knownGroups := cfg.IndexReleaseGroups()
 
 	for _, bump := range bumps {
-		entry := LogEntry{Content: bump.Message, Timestamp: 0, Commit: ""}
+		entry := LogEntry{Content: bump.Message, Message: bump.Message, Timestamp: 0, Commit: ""}
+		if bump.File != "" {
+			entry.File = filepath.Base(bump.File)
+		}
 		if bump.Commit != nil {
 			entry.Timestamp = bump.Commit.When.UnixNano()
+			entry.SHA = bump.Commit.SHA
+			entry.AuthorName = bump.Commit.AuthorName
+			entry.AuthorEmail = bump.Commit.AuthorEmail
 			entry.Commit = bump.Commit.SHA[:min(7, len(bump.Commit.SHA))]
 			entry.Content = fmt.Sprintf("%s: %s", entry.Commit, entry.Content)
 		}
@@ -116,13 +263,21 @@ func SquashBumps(ctx context.Context, logger *slog.Logger, bumps []ParsedBump, c
 				logger.WarnContext(ctx, "skipping bump for unknown group", slog.String("file", bump.File), slog.String("group", groupName))
 				continue
 			}
+			if level == "none" {
+				// The change needs no release of the group, see `bumper check`.
+				continue
+			}
 
 			if _, ok := statuses[groupName]; !ok {
-				statuses[groupName] = &ReleaseGroupStatus{
-					Level:     BumpLevelNone,
-					MajorLogs: []LogEntry{},
-					MinorLogs: []LogEntry{},
-					PatchLogs: []LogEntry{},
+				statuses[groupName] = newReleaseGroupStatus()
+			}
+
+			entry := entry
+			if category, ok := bump.Categories[groupName]; ok {
+				if changelog.IsCategory(category) {
+					entry.Category = category
+				} else {
+					logger.WarnContext(ctx, "unknown changelog category in bump file front matter", slog.String("file", bump.File), slog.String("group", groupName), slog.String("category", category))
 				}
 			}
 
@@ -154,18 +309,184 @@ func SquashBumps(ctx context.Context, logger *slog.Logger, bumps []ParsedBump, c
 		})
 	}
 
+	// A set bump can imply dependency bumps and vice versa, so both are
+	// applied until neither raises another level. Levels only ever increase,
+	// which bounds the loop.
+	for changed := true; changed; {
+		changed = propagateDependencies(ctx, logger, cfg, statuses)
+		changed = applyVersionSets(cfg, statuses) || changed
+	}
+
 	return statuses
 }
 
-func DeleteBumps(ctx context.Context, dir string) error {
-	pattern := BumpFilename(dir, "*")
-	matches, err := filepath.Glob(pattern)
+// applyVersionSets raises every group in a linked set with pending bumps to
+// the highest level in the set, and releases every group of a fixed set at
+// the highest level as soon as one of them has pending bumps. It reports
+// whether any level changed.
+func applyVersionSets(cfg *Config, statuses map[string]*ReleaseGroupStatus) bool {
+	changed := false
+
+	share := func(set []string, includeIdle bool) {
+		level := BumpLevelNone
+		for _, name := range set {
+			if status, ok := statuses[name]; ok {
+				level = max(level, status.Level)
+			}
+		}
+		if level == BumpLevelNone {
+			return
+		}
+
+		for _, name := range set {
+			status, ok := statuses[name]
+			if !ok {
+				if !includeIdle {
+					continue
+				}
+				status = newReleaseGroupStatus()
+				statuses[name] = status
+			}
+			if status.Level < level {
+				status.Level = level
+				changed = true
+			}
+		}
+	}
+
+	for _, set := range cfg.Fixed {
+		share(set, true)
+	}
+	for _, set := range cfg.Linked {
+		share(set, false)
+	}
+
+	return changed
+}
+
+// propagateDependencies cascades implied bumps from released groups to the
+// groups depending on them. Groups are visited in release order so that
+// transitive dependents see the implied bumps of their direct dependencies.
+// It is idempotent and reports whether any level or dependency changed.
+func propagateDependencies(ctx context.Context, logger *slog.Logger, cfg *Config, statuses map[string]*ReleaseGroupStatus) bool {
+	order, err := cfg.ReleaseOrder()
 	if err != nil {
-		return fmt.Errorf("glob bump files: %w", err)
+		// validateConfig rejects cycles, so this only guards configs that
+		// were built without loading.
+		logger.WarnContext(ctx, "skipping dependency propagation", slog.String("error", err.Error()))
+		return false
 	}
 
-	if len(matches) == 0 {
-		return nil
+	changed := false
+
+	groups := cfg.IndexReleaseGroups()
+	for _, name := range order {
+		for _, dep := range groups[name].DependsOn {
+			depStatus, ok := statuses[dep.Group]
+			if !ok {
+				continue
+			}
+
+			implied := dep.ImpliedLevel(depStatus.Level)
+			if implied == BumpLevelNone {
+				continue
+			}
+
+			status, ok := statuses[name]
+			if !ok {
+				status = newReleaseGroupStatus()
+				statuses[name] = status
+			}
+			if status.Level < implied {
+				status.Level = implied
+				changed = true
+			}
+
+			i := slices.IndexFunc(status.Dependencies, func(u DependencyUpdate) bool { return u.Group == dep.Group })
+			switch {
+			case i < 0:
+				status.Dependencies = append(status.Dependencies, DependencyUpdate{Group: dep.Group, Level: implied})
+				changed = true
+			case status.Dependencies[i].Level < implied:
+				status.Dependencies[i].Level = implied
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// FixedVersions computes the single version every group of a fixed set with
+// pending bumps is released as: the highest current version in the set
+// bumped by the set's level. released maps groups already released in this
+// batch to their versions; a set with a released member reuses that version,
+// since the member's current version has already moved. Groups that are not
+// in a fixed set are absent from the result.
+func FixedVersions(ctx context.Context, runner Runner, dir string, cfg *Config, statuses map[string]*ReleaseGroupStatus, pre *PreState, released map[string]string) (map[string]string, error) {
+	groups := cfg.IndexReleaseGroups()
+	versions := make(map[string]string)
+
+	for _, set := range cfg.Fixed {
+		level := BumpLevelNone
+		var channel *PreReleaseGroup
+		shared := ""
+		for _, name := range set {
+			if status, ok := statuses[name]; ok {
+				level = max(level, status.Level)
+			}
+			if p := pre.Groups[name]; p != nil && channel == nil {
+				channel = p
+			}
+			if version, ok := released[name]; ok {
+				shared = version
+			}
+		}
+		if level == BumpLevelNone {
+			continue
+		}
+
+		if shared == "" {
+			var highest *semver.Version
+			for _, name := range set {
+				current, err := GetCurrentVersion(ctx, runner, dir, groups[name])
+				if err != nil {
+					return nil, fmt.Errorf("release group %s: %w", name, err)
+				}
+				if highest == nil || current.GreaterThan(highest) {
+					highest = current
+				}
+			}
+
+			next, err := ComputeNextVersion(highest, level, channel)
+			if err != nil {
+				return nil, err
+			}
+			shared = next
+		}
+
+		for _, name := range set {
+			versions[name] = shared
+		}
+	}
+
+	return versions, nil
+}
+
+// PendingBumpFiles lists the bump files in dir that the next commit consumes.
+func PendingBumpFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(BumpFilename(dir, "*"))
+	if err != nil {
+		return nil, fmt.Errorf("glob bump files: %w", err)
+	}
+
+	return matches, nil
+}
+
+func DeleteBumps(ctx context.Context, dir string) error {
+	matches, err := PendingBumpFiles(dir)
+	if err != nil {
+		return err
 	}
 
 	for _, match := range matches {
@@ -237,20 +558,75 @@ func GetCurrentVersion(ctx context.Context, runner Runner, dir string, group Rel
 	return currentSemver, nil
 }
 
+// GetReleaseNotes returns the group's release notes for version, as printed
+// by its cat command.
+func GetReleaseNotes(ctx context.Context, runner Runner, dir string, group ReleaseGroup, version string) (string, error) {
+	inv, err := NewCatInvocation(group, version)
+	if err != nil {
+		return "", err
+	}
+
+	stdout := new(bytes.Buffer)
+	if err := runner.Run(ctx, dir, inv, stdout); err != nil {
+		return "", fmt.Errorf("execute cat command: %w", err)
+	}
+
+	return stdout.String(), nil
+}
+
 func GetNextVersion(ctx context.Context, runner Runner, dir string, group ReleaseGroup, level BumpLevel) (string, error) {
 	currentSemver, err := GetCurrentVersion(ctx, runner, dir, group)
 	if err != nil {
 		return "", err
 	}
 
+	next, err := incVersion(currentSemver, level)
+	if err != nil {
+		return "", err
+	}
+
+	return next.String(), nil
+}
+
+// GetNextPreVersion is GetNextVersion for a release group that may be in
+// prerelease mode. A nil pre means the group is not in prerelease mode and
+// the result matches GetNextVersion.
+func GetNextPreVersion(ctx context.Context, runner Runner, dir string, group ReleaseGroup, level BumpLevel, pre *PreReleaseGroup) (string, error) {
+	currentSemver, err := GetCurrentVersion(ctx, runner, dir, group)
+	if err != nil {
+		return "", err
+	}
+
+	return ComputeNextVersion(currentSemver, level, pre)
+}
+
+// ComputeNextVersion is the pure half of GetNextPreVersion, for callers that
+// already know the current version.
+func ComputeNextVersion(current *semver.Version, level BumpLevel, pre *PreReleaseGroup) (string, error) {
+	if pre != nil {
+		return pre.NextVersion(current, level)
+	}
+
+	next, err := incVersion(current, level)
+	if err != nil {
+		return "", err
+	}
+
+	return next.String(), nil
+}
+
+func incVersion(v *semver.Version, level BumpLevel) (*semver.Version, error) {
+	var next semver.Version
 	switch level {
 	case BumpLevelMajor:
-		return currentSemver.IncMajor().String(), nil
+		next = v.IncMajor()
 	case BumpLevelMinor:
-		return currentSemver.IncMinor().String(), nil
+		next = v.IncMinor()
 	case BumpLevelPatch:
-		return currentSemver.IncPatch().String(), nil
+		next = v.IncPatch()
 	default:
-		return "", errors.New("invalid bump level for next version")
+		return nil, errors.New("invalid bump level for next version")
 	}
+
+	return &next, nil
 }
@@ -3,6 +3,7 @@ package workspace
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -88,6 +89,25 @@ func TestExtractFrontMatter(t *testing.T) {
 	}
 }
 
+func TestReadBumpFileCategories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bump-remove.md")
+	content := "---\napi:\n  level: major\n  category: removed\nweb: patch\n---\n\nRemoved the v1 endpoints\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write bump file: %v", err)
+	}
+
+	bump, err := ReadBumpFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]string{"api": "major", "web": "patch"}; !reflect.DeepEqual(bump.Levels, want) {
+		t.Errorf("levels = %#v, want %#v", bump.Levels, want)
+	}
+	if want := map[string]string{"api": "removed"}; !reflect.DeepEqual(bump.Categories, want) {
+		t.Errorf("categories = %#v, want %#v", bump.Categories, want)
+	}
+}
+
 func commitAt(sha string, unixSec int64) *Commit {
 	return &Commit{SHA: sha, When: time.Unix(unixSec, 0)}
 }
@@ -117,6 +137,26 @@ func TestSquashBumps(t *testing.T) {
 		}
 	})
 
+	t.Run("categories apply to their group", func(t *testing.T) {
+		bumps := []ParsedBump{
+			{
+				File:       "bump-1.md",
+				Levels:     map[string]string{"api": "major", "web": "patch"},
+				Categories: map[string]string{"api": "removed", "web": "unknown"},
+				Message:    "removed endpoints",
+			},
+		}
+
+		statuses := SquashBumps(t.Context(), logger, bumps, cfg)
+
+		if got := statuses["api"].MajorLogs[0].Category; got != "removed" {
+			t.Errorf("api category = %q, want removed", got)
+		}
+		if got := statuses["web"].PatchLogs[0].Category; got != "" {
+			t.Errorf("web category = %q, want none for an unknown category", got)
+		}
+	})
+
 	t.Run("one bump file spanning multiple groups", func(t *testing.T) {
 		bumps := []ParsedBump{
 			{File: "bump-1.md", Levels: map[string]string{"api": "minor", "web": "patch"}, Message: "shared change"},
@@ -171,6 +211,32 @@ func TestSquashBumps(t *testing.T) {
 		}
 	})
 
+	t.Run("entries carry commit metadata", func(t *testing.T) {
+		commit := &Commit{SHA: "aaaaaaa2222", When: time.Unix(100, 0), AuthorName: "Ada", AuthorEmail: "ada@example.com"}
+		bumps := []ParsedBump{
+			{File: "/repo/.bumper/bump-1.md", Levels: map[string]string{"api": "patch"}, Message: "fix", Commit: commit},
+		}
+
+		statuses := SquashBumps(t.Context(), logger, bumps, cfg)
+
+		want := LogEntry{
+			Timestamp:   time.Unix(100, 0).UnixNano(),
+			Commit:      "aaaaaaa",
+			SHA:         "aaaaaaa2222",
+			Content:     "aaaaaaa: fix",
+			Message:     "fix",
+			AuthorName:  "Ada",
+			AuthorEmail: "ada@example.com",
+			File:        "bump-1.md",
+		}
+		if logs := statuses["api"].PatchLogs; len(logs) != 1 || logs[0] != want {
+			t.Errorf("patch logs = %+v, want [%+v]", logs, want)
+		}
+		if got := want.Date(); !got.Equal(time.Unix(100, 0)) {
+			t.Errorf("date = %v, want the commit date", got)
+		}
+	})
+
 	t.Run("unknown groups and levels are skipped", func(t *testing.T) {
 		bumps := []ParsedBump{
 			{File: "bump-1.md", Levels: map[string]string{"mobile": "minor"}, Message: "for unknown group"},
@@ -186,6 +252,18 @@ func TestSquashBumps(t *testing.T) {
 			t.Errorf("api level = %v, want none for unknown level", status.Level)
 		}
 	})
+
+	t.Run("none level releases nothing", func(t *testing.T) {
+		bumps := []ParsedBump{
+			{File: "bump-1.md", Levels: map[string]string{"api": "none"}, Message: "internal refactor"},
+		}
+
+		statuses := SquashBumps(t.Context(), logger, bumps, cfg)
+
+		if _, ok := statuses["api"]; ok {
+			t.Error("none level must not produce a status")
+		}
+	})
 }
 
 // CollectBumps composes gather and squash: files on disk plus fake provenance
@@ -232,3 +310,94 @@ func TestCollectBumpsComposesGatherAndSquash(t *testing.T) {
 		t.Errorf("major logs = %+v, want the newline-less entry with sha prefix", status.MajorLogs)
 	}
 }
+
+func TestSquashBumpsPropagatesDependencies(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	cfg := &Config{Groups: []ReleaseGroup{
+		{Name: "sdk"},
+		{Name: "cli", DependsOn: []Dependency{{Group: "sdk"}}},
+		{Name: "app", DependsOn: []Dependency{{Group: "cli", Policy: DependencyPolicyMatch}}},
+		{Name: "web", DependsOn: []Dependency{{Group: "sdk", Policy: DependencyPolicyMatch}}},
+		{Name: "docs", DependsOn: []Dependency{{Group: "sdk", Policy: DependencyPolicyNone}}},
+	}}
+	bumps := []ParsedBump{
+		{File: "bump-sdk.md", Levels: map[string]string{"sdk": "minor"}, Message: "New SDK API"},
+		{File: "bump-app.md", Levels: map[string]string{"app": "minor"}, Message: "App feature"},
+	}
+
+	statuses := SquashBumps(t.Context(), logger, bumps, cfg)
+
+	tests := []struct {
+		group     string
+		wantLevel BumpLevel
+		wantDeps  []DependencyUpdate
+	}{
+		{group: "sdk", wantLevel: BumpLevelMinor},
+		{group: "cli", wantLevel: BumpLevelPatch, wantDeps: []DependencyUpdate{{Group: "sdk", Level: BumpLevelPatch}}},
+		{group: "app", wantLevel: BumpLevelMinor, wantDeps: []DependencyUpdate{{Group: "cli", Level: BumpLevelPatch}}},
+		{group: "web", wantLevel: BumpLevelMinor, wantDeps: []DependencyUpdate{{Group: "sdk", Level: BumpLevelMinor}}},
+	}
+	for _, tt := range tests {
+		status, ok := statuses[tt.group]
+		if !ok {
+			t.Errorf("%s: expected a status", tt.group)
+			continue
+		}
+		if status.Level != tt.wantLevel {
+			t.Errorf("%s: level = %v, want %v", tt.group, status.Level, tt.wantLevel)
+		}
+		if !reflect.DeepEqual(status.Dependencies, tt.wantDeps) {
+			t.Errorf("%s: dependencies = %#v, want %#v", tt.group, status.Dependencies, tt.wantDeps)
+		}
+	}
+
+	if _, ok := statuses["docs"]; ok {
+		t.Error("docs: the none policy must not imply a bump")
+	}
+
+	cli := statuses["cli"]
+	cli.AddDependencyEntries(map[string]string{"sdk": "1.3.0"})
+	if len(cli.PatchLogs) != 1 || cli.PatchLogs[0].Content != "Updated dependency sdk to 1.3.0" {
+		t.Errorf("cli patch logs = %#v, want a synthesized dependency entry", cli.PatchLogs)
+	}
+}
+
+func TestSquashBumpsVersionSets(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	cfg := &Config{
+		Fixed:  [][]string{{"api", "web"}},
+		Linked: [][]string{{"cli", "sdk", "docs"}},
+		Groups: []ReleaseGroup{{Name: "api"}, {Name: "web"}, {Name: "cli"}, {Name: "sdk"}, {Name: "docs"}},
+	}
+	bumps := []ParsedBump{
+		{File: "bump-api.md", Levels: map[string]string{"api": "minor"}, Message: "API feature"},
+		{File: "bump-cli.md", Levels: map[string]string{"cli": "patch"}, Message: "CLI fix"},
+		{File: "bump-sdk.md", Levels: map[string]string{"sdk": "major"}, Message: "SDK break"},
+	}
+
+	statuses := SquashBumps(t.Context(), logger, bumps, cfg)
+
+	want := map[string]BumpLevel{
+		"api": BumpLevelMinor,
+		// Fixed: released with the set even without bumps of its own.
+		"web": BumpLevelMinor,
+		// Linked: raised to the highest level among releasing members.
+		"cli": BumpLevelMajor,
+		"sdk": BumpLevelMajor,
+	}
+	for group, level := range want {
+		status, ok := statuses[group]
+		if !ok {
+			t.Errorf("%s: expected a status", group)
+			continue
+		}
+		if status.Level != level {
+			t.Errorf("%s: level = %v, want %v", group, status.Level, level)
+		}
+	}
+
+	// Linked groups without pending bumps are not released.
+	if _, ok := statuses["docs"]; ok {
+		t.Error("docs: linked group without bumps must not be released")
+	}
+}
@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ChangelogInputEnv names the environment variable holding the path of the
+// ChangelogInput document passed to changelog commands.
+const ChangelogInputEnv = "BUMPER_CHANGELOG_INPUT"
+
+// ChangelogInput is the structured counterpart of the --major, --minor and
+// --patch flags passed to changelog commands. It is written as JSON to a
+// temporary file whose path is in BUMPER_CHANGELOG_INPUT.
+type ChangelogInput struct {
+	Group       string           `json:"group"`
+	DisplayName string           `json:"display_name,omitempty"`
+	Version     string           `json:"version"`
+	Major       []ChangelogEntry `json:"major"`
+	Minor       []ChangelogEntry `json:"minor"`
+	Patch       []ChangelogEntry `json:"patch"`
+}
+
+// ChangelogEntry is one changelog entry with the provenance of the bump that
+// produced it. Provenance fields are empty when unknown, e.g. outside a git
+// repository or for dependency updates.
+type ChangelogEntry struct {
+	// Content is the entry as passed in the flags, including the commit
+	// prefix.
+	Content string `json:"content"`
+	// Message is the bump message alone.
+	Message     string    `json:"message"`
+	SHA         string    `json:"sha,omitempty"`
+	AuthorName  string    `json:"author_name,omitempty"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	Date        time.Time `json:"date,omitzero"`
+	// File is the name of the bump file, e.g. "bump-happy-cats-run-fast.md".
+	File string `json:"file,omitempty"`
+	// Category is the entry's Keep a Changelog category, e.g. "removed",
+	// when the bump file sets one.
+	Category string `json:"category,omitempty"`
+}
+
+func newChangelogInput(group ReleaseGroup, nextVersion string, status *ReleaseGroupStatus) ChangelogInput {
+	return ChangelogInput{
+		Group:       group.Name,
+		DisplayName: group.DisplayName,
+		Version:     nextVersion,
+		Major:       newChangelogEntries(status.MajorLogs),
+		Minor:       newChangelogEntries(status.MinorLogs),
+		Patch:       newChangelogEntries(status.PatchLogs),
+	}
+}
+
+func newChangelogEntries(logs []LogEntry) []ChangelogEntry {
+	entries := make([]ChangelogEntry, 0, len(logs))
+	for _, log := range logs {
+		message := log.Message
+		if message == "" {
+			message = log.Content
+		}
+
+		entries = append(entries, ChangelogEntry{
+			Content:     log.Content,
+			Message:     message,
+			SHA:         log.SHA,
+			AuthorName:  log.AuthorName,
+			AuthorEmail: log.AuthorEmail,
+			Date:        log.Date(),
+			File:        log.File,
+			Category:    log.Category,
+		})
+	}
+
+	return entries
+}
+
+// ReadChangelogInput reads the document named by BUMPER_CHANGELOG_INPUT, as
+// amendlog:default does.
+func ReadChangelogInput(path string) (*ChangelogInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read changelog input: %w", err)
+	}
+
+	var input ChangelogInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("decode changelog input %s: %w", path, err)
+	}
+
+	return &input, nil
+}
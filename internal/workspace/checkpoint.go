@@ -1,20 +1,23 @@
 package workspace
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/BurntSushi/toml"
 )
 
 // CommitCheckpoint records the progress of a partially failed `bumper commit`
-// so a retry can skip release groups whose commands already succeeded. It is
-// written after each group completes and removed once the whole batch has
-// been released.
+// so a retry can skip release groups and steps whose commands already
+// succeeded. It is written after each step of each group and removed once
+// the whole batch has been released.
 type CommitCheckpoint struct {
 	// Batch fingerprints the pending bump files (base name -> content hash)
 	// the checkpoint belongs to. A retry with different pending bumps is a
@@ -23,6 +26,60 @@ type CommitCheckpoint struct {
 	// Released maps release group names to the version released for them in
 	// a previous attempt at this batch.
 	Released map[string]string `toml:"released"`
+	// Previous maps the released groups to their version before the
+	// release, for the release manifest.
+	Previous map[string]string `toml:"previous,omitempty"`
+	// Progress maps the release groups whose release was interrupted to the
+	// steps completed for them. A group moves to Released once all its steps
+	// are done.
+	Progress map[string]GroupProgress `toml:"progress,omitempty"`
+}
+
+// CommitStep is a step of releasing one group. Steps complete in the order
+// they are declared.
+type CommitStep string
+
+const (
+	// CommitStepVersion is the computation of the group's next version.
+	CommitStepVersion CommitStep = "version"
+	// CommitStepNext is the run of next_cmd, applying the next version.
+	CommitStepNext CommitStep = "next"
+	// CommitStepChangelog is the run of changelog_cmd, amending the
+	// changelog.
+	CommitStepChangelog CommitStep = "changelog"
+)
+
+var commitSteps = []CommitStep{CommitStepVersion, CommitStepNext, CommitStepChangelog}
+
+// GroupProgress records how far the release of a group got.
+type GroupProgress struct {
+	// Version is the version the group is being released as. Retries reuse
+	// it instead of bumping the current version again, which next_cmd may
+	// already have moved.
+	Version string `toml:"version"`
+	// Previous is the group's version before the release. It moves to
+	// CommitCheckpoint.Previous once the group is released.
+	Previous string `toml:"previous,omitempty"`
+	// Step is the last step completed.
+	Step CommitStep `toml:"step"`
+}
+
+// Done reports whether step has completed.
+func (p GroupProgress) Done(step CommitStep) bool {
+	return slices.Index(commitSteps, p.Step) >= slices.Index(commitSteps, step)
+}
+
+// Targets maps the groups released or being released to their versions.
+func (c *CommitCheckpoint) Targets() map[string]string {
+	targets := maps.Clone(c.Released)
+	if targets == nil {
+		targets = map[string]string{}
+	}
+	for name, progress := range c.Progress {
+		targets[name] = progress.Version
+	}
+
+	return targets
 }
 
 func CommitCheckpointFilename(base string) string {
@@ -66,13 +123,12 @@ func LoadCommitCheckpoint(dir string) (*CommitCheckpoint, error) {
 }
 
 func SaveCommitCheckpoint(dir string, checkpoint *CommitCheckpoint) error {
-	f, err := os.Create(CommitCheckpointFilename(dir))
-	if err != nil {
-		return fmt.Errorf("create commit checkpoint: %w", err)
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(checkpoint); err != nil {
+		return fmt.Errorf("encode commit checkpoint: %w", err)
 	}
-	defer f.Close()
 
-	if err := toml.NewEncoder(f).Encode(checkpoint); err != nil {
+	if err := WriteFileAtomic(CommitCheckpointFilename(dir), buf.Bytes(), 0644); err != nil {
 		return fmt.Errorf("write commit checkpoint: %w", err)
 	}
 
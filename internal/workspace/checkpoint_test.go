@@ -31,6 +31,7 @@ func TestCommitCheckpointRoundTrip(t *testing.T) {
 	saved := &CommitCheckpoint{
 		Batch:    map[string]string{"bump-a.md": "abc123"},
 		Released: map[string]string{"a": "1.1.0"},
+		Progress: map[string]GroupProgress{"b": {Version: "2.0.0", Step: CommitStepNext}},
 	}
 	if err := SaveCommitCheckpoint(dir, saved); err != nil {
 		t.Fatalf("save: %v", err)
@@ -92,3 +93,21 @@ func TestBumpBatchFingerprint(t *testing.T) {
 		t.Error("fingerprint unchanged after bump file content changed")
 	}
 }
+
+func TestGroupProgressDone(t *testing.T) {
+	progress := GroupProgress{Version: "1.1.0", Step: CommitStepNext}
+	for step, want := range map[CommitStep]bool{
+		CommitStepVersion:   true,
+		CommitStepNext:      true,
+		CommitStepChangelog: false,
+	} {
+		if got := progress.Done(step); got != want {
+			t.Errorf("Done(%s) = %v, want %v", step, got, want)
+		}
+	}
+
+	checkpoint := &CommitCheckpoint{Released: map[string]string{"a": "1.1.0"}, Progress: map[string]GroupProgress{"b": progress}}
+	if got, want := checkpoint.Targets(), map[string]string{"a": "1.1.0", "b": "1.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+}
@@ -2,7 +2,11 @@ package workspace
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/disintegrator/bumper/internal/changelog"
 )
 
 type BumpLevel int
@@ -29,8 +33,72 @@ func (b BumpLevel) String() string {
 	}
 }
 
+// ParseBumpLevel is the inverse of BumpLevel.String. The empty string parses
+// to BumpLevelNone.
+func ParseBumpLevel(s string) (BumpLevel, error) {
+	switch s {
+	case "":
+		return BumpLevelNone, nil
+	case "patch":
+		return BumpLevelPatch, nil
+	case "minor":
+		return BumpLevelMinor, nil
+	case "major":
+		return BumpLevelMajor, nil
+	default:
+		return BumpLevelNone, fmt.Errorf("unknown bump level: %q", s)
+	}
+}
+
+func (b BumpLevel) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *BumpLevel) UnmarshalText(text []byte) error {
+	level, err := ParseBumpLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*b = level
+	return nil
+}
+
 type Config struct {
-	Groups []ReleaseGroup `json:"groups,omitempty,omitzero" toml:"groups,omitempty,omitzero" yaml:"groups,omitempty,omitzero"`
+	// Fixed lists sets of release groups that always release together under
+	// a single shared version.
+	Fixed [][]string `json:"fixed,omitempty,omitzero" toml:"fixed,omitempty,omitzero" yaml:"fixed,omitempty,omitzero"`
+	// Linked lists sets of release groups whose pending releases share the
+	// highest bump level among them while keeping separate versions.
+	Linked [][]string `json:"linked,omitempty,omitzero" toml:"linked,omitempty,omitzero" yaml:"linked,omitempty,omitzero"`
+	// CommitMessage is the text/template for the release commit created by
+	// `bumper commit --git-commit`. Defaults to DefaultCommitMessage.
+	CommitMessage string `json:"commit_message,omitempty" toml:"commit_message,omitempty" yaml:"commit_message,omitempty"`
+	// SubprocessBuiltins runs group commands that invoke bumper's builtins
+	// as child processes, like any other command, instead of in-process.
+	SubprocessBuiltins bool           `json:"subprocess_builtins,omitempty" toml:"subprocess_builtins,omitempty" yaml:"subprocess_builtins,omitempty"`
+	Groups             []ReleaseGroup `json:"groups,omitempty,omitzero" toml:"groups,omitempty,omitzero" yaml:"groups,omitempty,omitzero"`
+}
+
+// FixedSet returns the fixed set containing the named group, or nil when the
+// group is not part of one.
+func (c *Config) FixedSet(group string) []string {
+	return findVersionSet(c.Fixed, group)
+}
+
+// LinkedSet returns the linked set containing the named group, or nil when
+// the group is not part of one.
+func (c *Config) LinkedSet(group string) []string {
+	return findVersionSet(c.Linked, group)
+}
+
+func findVersionSet(sets [][]string, group string) []string {
+	for _, set := range sets {
+		if slices.Contains(set, group) {
+			return set
+		}
+	}
+
+	return nil
 }
 
 func (c *Config) IndexReleaseGroups() map[string]ReleaseGroup {
@@ -42,12 +110,165 @@ func (c *Config) IndexReleaseGroups() map[string]ReleaseGroup {
 }
 
 type ReleaseGroup struct {
-	Name         string   `json:"name" toml:"name" yaml:"name"`
-	DisplayName  string   `json:"display_name,omitempty" toml:"display_name,omitempty" yaml:"display_name,omitempty"`
-	ChangelogCMD []string `json:"changelog_cmd,omitempty,omitzero" toml:"changelog_cmd,omitempty,omitzero" yaml:"changelog_cmd,omitempty,omitzero"`
-	CatCMD       []string `json:"cat_cmd,omitempty,omitzero" toml:"cat_cmd,omitempty,omitzero" yaml:"cat_cmd,omitempty,omitzero"`
-	CurrentCMD   []string `json:"current_cmd,omitempty,omitzero" toml:"current_cmd,omitempty,omitzero" yaml:"current_cmd,omitempty,omitzero"`
-	NextCMD      []string `json:"next_cmd,omitempty,omitzero" toml:"next_cmd,omitempty,omitzero" yaml:"next_cmd,omitempty,omitzero"`
+	Name         string       `json:"name" toml:"name" yaml:"name"`
+	DisplayName  string       `json:"display_name,omitempty" toml:"display_name,omitempty" yaml:"display_name,omitempty"`
+	ChangelogCMD []string     `json:"changelog_cmd,omitempty,omitzero" toml:"changelog_cmd,omitempty,omitzero" yaml:"changelog_cmd,omitempty,omitzero"`
+	CatCMD       []string     `json:"cat_cmd,omitempty,omitzero" toml:"cat_cmd,omitempty,omitzero" yaml:"cat_cmd,omitempty,omitzero"`
+	CurrentCMD   []string     `json:"current_cmd,omitempty,omitzero" toml:"current_cmd,omitempty,omitzero" yaml:"current_cmd,omitempty,omitzero"`
+	NextCMD      []string     `json:"next_cmd,omitempty,omitzero" toml:"next_cmd,omitempty,omitzero" yaml:"next_cmd,omitempty,omitzero"`
+	DependsOn    []Dependency `json:"depends_on,omitempty,omitzero" toml:"depends_on,omitempty,omitzero" yaml:"depends_on,omitempty,omitzero"`
+	// TagFormat opts the group into git tagging. It is the tag name with a
+	// {{version}} placeholder, e.g. "api/v{{version}}".
+	TagFormat string `json:"tag_format,omitempty" toml:"tag_format,omitempty" yaml:"tag_format,omitempty"`
+	// Scopes are the conventional commit scopes attributed to the group by
+	// `bumper infer`, in addition to the group's name.
+	Scopes []string `json:"scopes,omitempty,omitzero" toml:"scopes,omitempty,omitzero" yaml:"scopes,omitempty,omitzero"`
+	// Paths are the glob patterns, relative to the workspace root, of the
+	// files the group owns. See OwnsPath.
+	Paths []string `json:"paths,omitempty,omitzero" toml:"paths,omitempty,omitzero" yaml:"paths,omitempty,omitzero"`
+	// ChangelogPath is the changelog file, relative to the workspace root,
+	// used by the default changelog builtins. See ChangelogFilename.
+	ChangelogPath string `json:"changelog_path,omitempty" toml:"changelog_path,omitempty" yaml:"changelog_path,omitempty"`
+	// ChangelogFormat is the layout written by the default changelog
+	// builtins: "default" or "keepachangelog". See changelog.Format.
+	ChangelogFormat string `json:"changelog_format,omitempty" toml:"changelog_format,omitempty" yaml:"changelog_format,omitempty"`
+	// ChangelogTemplate is the Go template, relative to the .bumper
+	// directory, rendering the group's release sections in the default
+	// changelog builtins. See changelog.Template.
+	ChangelogTemplate string `json:"changelog_template,omitempty" toml:"changelog_template,omitempty" yaml:"changelog_template,omitempty"`
+}
+
+// ChangelogTemplateFilename returns the path of the group's changelog
+// template within the workspace rooted at base, or "" when it has none.
+func (g ReleaseGroup) ChangelogTemplateFilename(base string) string {
+	if g.ChangelogTemplate == "" {
+		return ""
+	}
+
+	return filepath.Join(Dir(base), filepath.FromSlash(g.ChangelogTemplate))
+}
+
+// DefaultChangelogPath is the changelog used by groups without a
+// changelog_path.
+const DefaultChangelogPath = "CHANGELOG.md"
+
+// ChangelogFilename returns the path of the group's changelog within the
+// workspace rooted at base: its changelog_path, or DefaultChangelogPath.
+func (g ReleaseGroup) ChangelogFilename(base string) string {
+	path := g.ChangelogPath
+	if path == "" {
+		path = DefaultChangelogPath
+	}
+
+	return filepath.Join(base, filepath.FromSlash(path))
+}
+
+// MatchesScope reports whether a conventional commit scope belongs to the
+// group.
+func (g ReleaseGroup) MatchesScope(scope string) bool {
+	return scope == g.Name || slices.Contains(g.Scopes, scope)
+}
+
+// tagVersionPlaceholder is replaced by the released version in TagFormat.
+const tagVersionPlaceholder = "{{version}}"
+
+// TagName renders the group's tag for version, or "" when the group does not
+// opt into tagging.
+func (g ReleaseGroup) TagName(version string) string {
+	if g.TagFormat == "" {
+		return ""
+	}
+
+	return strings.ReplaceAll(g.TagFormat, tagVersionPlaceholder, version)
+}
+
+// DependencyPolicy decides the bump a release group receives when a group it
+// depends on is released.
+type DependencyPolicy string
+
+const (
+	// DependencyPolicyPatch releases the dependent with at least a patch bump.
+	// It is the default when no policy is set.
+	DependencyPolicyPatch DependencyPolicy = "patch"
+	// DependencyPolicyMatch releases the dependent with at least the bump
+	// level of the dependency.
+	DependencyPolicyMatch DependencyPolicy = "match"
+	// DependencyPolicyNone only orders releases: the dependent is released
+	// after the dependency but not bumped because of it.
+	DependencyPolicyNone DependencyPolicy = "none"
+)
+
+// Dependency declares that a release group consumes another one.
+type Dependency struct {
+	Group  string           `json:"group" toml:"group" yaml:"group"`
+	Policy DependencyPolicy `json:"policy,omitempty" toml:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// ImpliedLevel is the bump level the dependent receives when the dependency
+// is released at level.
+func (d Dependency) ImpliedLevel(level BumpLevel) BumpLevel {
+	if level == BumpLevelNone {
+		return BumpLevelNone
+	}
+
+	switch d.Policy {
+	case DependencyPolicyMatch:
+		return level
+	case DependencyPolicyNone:
+		return BumpLevelNone
+	default:
+		return BumpLevelPatch
+	}
+}
+
+// ReleaseOrder returns the release group names ordered so that every group
+// comes after the groups it depends on. Groups that do not depend on each
+// other are ordered by name. It fails when the dependencies form a cycle.
+func (c *Config) ReleaseOrder() ([]string, error) {
+	indegree := make(map[string]int, len(c.Groups))
+	dependents := make(map[string][]string, len(c.Groups))
+	for _, g := range c.Groups {
+		indegree[g.Name] += 0
+		for _, dep := range g.DependsOn {
+			indegree[g.Name]++
+			dependents[dep.Group] = append(dependents[dep.Group], g.Name)
+		}
+	}
+
+	ready := make([]string, 0, len(indegree))
+	for name, n := range indegree {
+		if n == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(indegree))
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, dependent := range dependents[name] {
+			indegree[dependent]--
+			if indegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(indegree) {
+		cyclic := make([]string, 0, len(indegree)-len(order))
+		for name, n := range indegree {
+			if n > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		slices.Sort(cyclic)
+		return nil, fmt.Errorf("dependency cycle between release groups: %s", strings.Join(cyclic, ", "))
+	}
+
+	return order, nil
 }
 
 type invalidReleaseGroupConfigError struct {
@@ -94,6 +315,7 @@ func validateConfig(cfg *Config) error {
 	groupErrors := []*invalidReleaseGroupConfigError{}
 
 	seenNames := make(map[string]struct{})
+	knownGroups := cfg.IndexReleaseGroups()
 
 	for i, group := range cfg.Groups {
 		gerr := invalidReleaseGroupConfigError{
@@ -125,11 +347,66 @@ func validateConfig(cfg *Config) error {
 			gerr.errs = append(gerr.errs, fmt.Errorf("no next command defined"))
 		}
 
+		for _, pattern := range group.Paths {
+			if err := validatePathGlob(pattern); err != nil {
+				gerr.errs = append(gerr.errs, err)
+			}
+		}
+
+		if group.ChangelogPath != "" && !filepath.IsLocal(filepath.FromSlash(group.ChangelogPath)) {
+			gerr.errs = append(gerr.errs, fmt.Errorf("changelog path %q must be relative to the workspace root and stay within it", group.ChangelogPath))
+		}
+
+		if group.ChangelogTemplate != "" && !filepath.IsLocal(filepath.FromSlash(group.ChangelogTemplate)) {
+			gerr.errs = append(gerr.errs, fmt.Errorf("changelog template %q must be relative to the .bumper directory and stay within it", group.ChangelogTemplate))
+		}
+
+		if _, err := changelog.ParseFormat(group.ChangelogFormat); err != nil {
+			gerr.errs = append(gerr.errs, err)
+		}
+
+		if group.TagFormat != "" && !strings.Contains(group.TagFormat, tagVersionPlaceholder) {
+			gerr.errs = append(gerr.errs, fmt.Errorf("tag format %q has no %s placeholder", group.TagFormat, tagVersionPlaceholder))
+		}
+
+		for _, dep := range group.DependsOn {
+			switch {
+			case dep.Group == group.Name:
+				gerr.errs = append(gerr.errs, fmt.Errorf("release group cannot depend on itself"))
+			case dep.Group == "":
+				gerr.errs = append(gerr.errs, fmt.Errorf("dependency has no group set"))
+			default:
+				if _, ok := knownGroups[dep.Group]; !ok {
+					gerr.errs = append(gerr.errs, fmt.Errorf("depends on unknown release group %q", dep.Group))
+				}
+			}
+
+			switch dep.Policy {
+			case "", DependencyPolicyPatch, DependencyPolicyMatch, DependencyPolicyNone:
+			default:
+				gerr.errs = append(gerr.errs, fmt.Errorf("unknown policy %q for dependency %q", dep.Policy, dep.Group))
+			}
+		}
+
 		if len(gerr.errs) > 0 {
 			groupErrors = append(groupErrors, &gerr)
 		}
 	}
 
+	globalErrors = append(globalErrors, validateVersionSets(cfg, knownGroups)...)
+
+	if _, err := RenderCommitMessage(cfg.CommitMessage, nil); err != nil {
+		globalErrors = append(globalErrors, err)
+	}
+
+	// Cycles only make sense to report once every dependency names a known
+	// group other than its own.
+	if len(groupErrors) == 0 {
+		if _, err := cfg.ReleaseOrder(); err != nil {
+			globalErrors = append(globalErrors, err)
+		}
+	}
+
 	if len(globalErrors) > 0 || len(groupErrors) > 0 {
 		return &InvalidConfigError{
 			globalErrors: globalErrors,
@@ -139,3 +416,38 @@ func validateConfig(cfg *Config) error {
 
 	return nil
 }
+
+// validateVersionSets checks the fixed and linked sets: each must name at
+// least two known groups, and a group may belong to at most one set since
+// overlapping sets would have to be merged to be enforced consistently.
+func validateVersionSets(cfg *Config, knownGroups map[string]ReleaseGroup) []error {
+	var errs []error
+	membership := make(map[string]string)
+
+	check := func(kind string, sets [][]string) {
+		for i, set := range sets {
+			label := fmt.Sprintf("%s set %d", kind, i)
+			if len(set) < 2 {
+				errs = append(errs, fmt.Errorf("%s must contain at least two release groups", label))
+			}
+
+			for _, name := range set {
+				if _, ok := knownGroups[name]; !ok {
+					errs = append(errs, fmt.Errorf("%s references unknown release group %q", label, name))
+					continue
+				}
+
+				if other, ok := membership[name]; ok {
+					errs = append(errs, fmt.Errorf("release group %q is in both %s and %s", name, other, label))
+					continue
+				}
+				membership[name] = label
+			}
+		}
+	}
+
+	check("fixed", cfg.Fixed)
+	check("linked", cfg.Linked)
+
+	return errs
+}
@@ -0,0 +1,199 @@
+package workspace
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func validGroup(name string, deps ...Dependency) ReleaseGroup {
+	return ReleaseGroup{
+		Name:         name,
+		ChangelogCMD: []string{"true"},
+		CatCMD:       []string{"true"},
+		CurrentCMD:   []string{"true"},
+		NextCMD:      []string{"true"},
+		DependsOn:    deps,
+	}
+}
+
+func TestReleaseOrder(t *testing.T) {
+	cfg := &Config{Groups: []ReleaseGroup{
+		validGroup("app", Dependency{Group: "cli"}),
+		validGroup("cli", Dependency{Group: "sdk"}),
+		validGroup("docs"),
+		validGroup("sdk"),
+	}}
+
+	order, err := cfg.ReleaseOrder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"docs", "sdk", "cli", "app"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestValidateConfigDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []ReleaseGroup
+		wantErr string
+	}{
+		{
+			name:    "unknown dependency",
+			groups:  []ReleaseGroup{validGroup("cli", Dependency{Group: "sdk"})},
+			wantErr: `depends on unknown release group "sdk"`,
+		},
+		{
+			name:    "self dependency",
+			groups:  []ReleaseGroup{validGroup("cli", Dependency{Group: "cli"})},
+			wantErr: "cannot depend on itself",
+		},
+		{
+			name:    "unknown policy",
+			groups:  []ReleaseGroup{validGroup("cli", Dependency{Group: "sdk", Policy: "always"}), validGroup("sdk")},
+			wantErr: `unknown policy "always"`,
+		},
+		{
+			name: "cycle",
+			groups: []ReleaseGroup{
+				validGroup("a", Dependency{Group: "c"}),
+				validGroup("b", Dependency{Group: "a"}),
+				validGroup("c", Dependency{Group: "b"}),
+				validGroup("d"),
+			},
+			wantErr: "dependency cycle between release groups: a, b, c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(&Config{Groups: tt.groups})
+			var invalidErr *InvalidConfigError
+			if !errors.As(err, &invalidErr) {
+				t.Fatalf("err = %v, want an InvalidConfigError", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want it to contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+
+	valid := &Config{Groups: []ReleaseGroup{
+		validGroup("cli", Dependency{Group: "sdk", Policy: DependencyPolicyMatch}),
+		validGroup("sdk"),
+	}}
+	if err := validateConfig(valid); err != nil {
+		t.Errorf("unexpected error for valid dependencies: %v", err)
+	}
+}
+
+func TestValidateConfigVersionSets(t *testing.T) {
+	groups := []ReleaseGroup{validGroup("a"), validGroup("b"), validGroup("c")}
+
+	tests := []struct {
+		name    string
+		fixed   [][]string
+		linked  [][]string
+		wantErr string
+	}{
+		{
+			name:    "unknown group",
+			fixed:   [][]string{{"a", "z"}},
+			wantErr: `fixed set 0 references unknown release group "z"`,
+		},
+		{
+			name:    "single group",
+			linked:  [][]string{{"a"}},
+			wantErr: "linked set 0 must contain at least two release groups",
+		},
+		{
+			name:    "overlapping fixed sets",
+			fixed:   [][]string{{"a", "b"}, {"b", "c"}},
+			wantErr: `release group "b" is in both fixed set 0 and fixed set 1`,
+		},
+		{
+			name:    "group both fixed and linked",
+			fixed:   [][]string{{"a", "b"}},
+			linked:  [][]string{{"b", "c"}},
+			wantErr: `release group "b" is in both fixed set 0 and linked set 0`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(&Config{Fixed: tt.fixed, Linked: tt.linked, Groups: groups})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want it to contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+
+	valid := &Config{Fixed: [][]string{{"a", "b"}}, Groups: groups}
+	if err := validateConfig(valid); err != nil {
+		t.Errorf("unexpected error for a valid fixed set: %v", err)
+	}
+}
+
+func TestChangelogPath(t *testing.T) {
+	group := validGroup("api")
+	if got, want := group.ChangelogFilename("/repo"), filepath.Join("/repo", "CHANGELOG.md"); got != want {
+		t.Errorf("default changelog = %q, want %q", got, want)
+	}
+
+	group.ChangelogPath = "services/api/CHANGELOG.md"
+	if got, want := group.ChangelogFilename("/repo"), filepath.Join("/repo", "services", "api", "CHANGELOG.md"); got != want {
+		t.Errorf("changelog = %q, want %q", got, want)
+	}
+	if err := validateConfig(&Config{Groups: []ReleaseGroup{group}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, path := range []string{"/abs/CHANGELOG.md", "../CHANGELOG.md"} {
+		group.ChangelogPath = path
+		if err := validateConfig(&Config{Groups: []ReleaseGroup{group}}); err == nil {
+			t.Errorf("expected an error for changelog path %q", path)
+		}
+	}
+}
+
+func TestValidateConfigChangelogFormat(t *testing.T) {
+	group := validGroup("api")
+	for _, format := range []string{"", "default", "keepachangelog"} {
+		group.ChangelogFormat = format
+		if err := validateConfig(&Config{Groups: []ReleaseGroup{group}}); err != nil {
+			t.Errorf("unexpected error for format %q: %v", format, err)
+		}
+	}
+
+	group.ChangelogFormat = "markdown"
+	err := validateConfig(&Config{Groups: []ReleaseGroup{group}})
+	if err == nil || !strings.Contains(err.Error(), `unknown changelog format "markdown"`) {
+		t.Errorf("err = %v, want an unknown changelog format error", err)
+	}
+}
+
+func TestChangelogTemplate(t *testing.T) {
+	group := validGroup("api")
+	if got := group.ChangelogTemplateFilename("/repo"); got != "" {
+		t.Errorf("template = %q, want none", got)
+	}
+
+	group.ChangelogTemplate = "templates/changelog.tmpl"
+	if got, want := group.ChangelogTemplateFilename("/repo"), filepath.Join("/repo", ".bumper", "templates", "changelog.tmpl"); got != want {
+		t.Errorf("template = %q, want %q", got, want)
+	}
+
+	group.ChangelogTemplate = "../changelog.tmpl"
+	if err := validateConfig(&Config{Groups: []ReleaseGroup{group}}); err == nil {
+		t.Error("expected an error for a template outside the .bumper directory")
+	}
+}
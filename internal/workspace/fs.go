@@ -1,6 +1,7 @@
 package workspace
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -50,18 +51,16 @@ func Initialize(baseDir string) error {
 	}
 
 	var cfg Config
-	f, err := os.OpenFile(configFilename, os.O_RDWR|os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return fmt.Errorf("encode: %s: %w", configFilename, err)
+	}
+
+	err = createFileAtomic(configFilename, buf.Bytes(), 0644)
 	switch {
 	case errors.Is(err, os.ErrExist):
 		return nil
 	case err != nil:
-		return fmt.Errorf("open: %s: %w", configFilename, err)
-	default:
-		// proceed
-	}
-	defer f.Close()
-
-	if err := toml.NewEncoder(f).Encode(cfg); err != nil {
 		return fmt.Errorf("write: %s: %w", configFilename, err)
 	}
 
@@ -91,15 +90,85 @@ func SaveConfig(baseDir string, cfg *Config) error {
 
 	configFilename := ConfigFilename(baseDir)
 
-	f, err := os.OpenFile(configFilename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return fmt.Errorf("open: %s: %w", configFilename, err)
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return fmt.Errorf("encode: %s: %w", configFilename, err)
 	}
-	defer f.Close()
 
-	if err := toml.NewEncoder(f).Encode(cfg); err != nil {
+	if err := WriteFileAtomic(configFilename, buf.Bytes(), 0644); err != nil {
 		return fmt.Errorf("write: %s: %w", configFilename, err)
 	}
 
 	return nil
 }
+
+// WriteFileAtomic writes data to filename through a temporary file in the
+// same directory that is then renamed over filename, so readers never see a
+// partially written file. An existing file keeps its permissions and a
+// symbolic link is written through; a new file is created with perm.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := writeTempFile(filename, data, perm)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+
+	return nil
+}
+
+// createFileAtomic is WriteFileAtomic for a file that must not exist yet.
+// The file is linked into place, which fails with os.ErrExist when another
+// process created it first.
+func createFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := writeTempFile(filename, data, perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if err := os.Link(tmp, filename); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("link %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+// writeTempFile writes data to a new temporary file next to filename and
+// returns its path.
+func writeTempFile(filename string, data []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("create temporary file: %w", err)
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("write temporary file: %w", err)
+	}
+
+	return f.Name(), nil
+}
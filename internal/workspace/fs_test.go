@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "versions.toml")
+
+	if err := WriteFileAtomic(filename, []byte("a\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.Chmod(filename, 0o640); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if err := WriteFileAtomic(filename, []byte("b\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(content) != "b\n" {
+		t.Errorf("content = %q, want %q", content, "b\n")
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want the existing file's 0640 kept", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("entries = %v, want no temporary files left behind", entries)
+	}
+
+	if err := createFileAtomic(filename, []byte("c\n"), 0o644); !errors.Is(err, os.ErrExist) {
+		t.Errorf("createFileAtomic error = %v, want os.ErrExist", err)
+	}
+}
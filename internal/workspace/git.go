@@ -72,8 +72,10 @@ func (g *GitProvenance) Resolve(ctx context.Context, bumpFiles []string) (map[st
 				unresolved = append(unresolved, f)
 			} else {
 				info[f] = Commit{
-					SHA:  commit.Hash.String(),
-					When: commit.Committer.When,
+					SHA:         commit.Hash.String(),
+					When:        commit.Committer.When,
+					AuthorName:  commit.Author.Name,
+					AuthorEmail: commit.Author.Email,
 				}
 			}
 		}
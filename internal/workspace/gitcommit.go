@@ -0,0 +1,126 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"text/template"
+
+	"github.com/go-git/go-git/v6"
+)
+
+// DefaultCommitMessage is the release commit message template used when the
+// config does not set commit_message.
+const DefaultCommitMessage = `chore: release
+
+{{ range .Releases }}- {{ .Group }} {{ .Version }}
+{{ end }}`
+
+// Release is a released group and version, as listed in the release commit
+// message.
+type Release struct {
+	Group       string
+	DisplayName string
+	Version     string
+}
+
+// RenderCommitMessage renders the release commit message template format
+// (DefaultCommitMessage when empty) for releases.
+func RenderCommitMessage(format string, releases []Release) (string, error) {
+	if format == "" {
+		format = DefaultCommitMessage
+	}
+
+	tmpl, err := template.New("commit_message").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("parse commit message template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Releases []Release }{Releases: releases}); err != nil {
+		return "", fmt.Errorf("render commit message template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// WorktreeChanges lists the files in the git worktree containing dir that
+// differ from HEAD, staged or not, including untracked files. Paths are
+// relative to the repository root and sorted.
+func WorktreeChanges(dir string) ([]string, error) {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree status: %w", err)
+	}
+
+	changed := make([]string, 0, len(status))
+	for path, fs := range status {
+		if isLockPath(path) {
+			continue
+		}
+		if fs.Staging != git.Unmodified || fs.Worktree != git.Unmodified {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+
+	return changed, nil
+}
+
+// TouchedFiles returns the paths in after that are not in before: the files
+// changed between two WorktreeChanges snapshots.
+func TouchedFiles(before []string, after []string) []string {
+	seen := make(map[string]struct{}, len(before))
+	for _, path := range before {
+		seen[path] = struct{}{}
+	}
+
+	touched := make(map[string]struct{}, len(after))
+	for _, path := range after {
+		if _, ok := seen[path]; !ok {
+			touched[path] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(touched))
+}
+
+// CommitFiles stages exactly paths, relative to the repository root, in the
+// git repository containing dir and commits them with message. Deleted files
+// are staged as removals. The author is read from the git configuration. It
+// returns the new commit's hash.
+func CommitFiles(dir string, paths []string, message string) (string, error) {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return "", err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("get worktree: %w", err)
+	}
+
+	for _, path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return "", fmt.Errorf("stage %s: %w", path, err)
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{})
+	if err != nil {
+		return "", fmt.Errorf("create commit: %w", err)
+	}
+
+	return hash.String(), nil
+}
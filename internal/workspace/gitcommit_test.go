@@ -0,0 +1,127 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestRenderCommitMessage(t *testing.T) {
+	releases := []Release{
+		{Group: "sdk", Version: "2.0.0"},
+		{Group: "api", DisplayName: "API", Version: "1.2.0"},
+	}
+
+	got, err := RenderCommitMessage("", releases)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "chore: release\n\n- sdk 2.0.0\n- api 1.2.0\n"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	got, err = RenderCommitMessage("release {{ range .Releases }}{{ .Group }}@{{ .Version }} {{ end }}", releases)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "release sdk@2.0.0 api@1.2.0 "; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	if _, err := RenderCommitMessage("{{ .Releases", nil); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestCommitFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("read repository config: %v", err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("write repository config: %v", err)
+	}
+
+	write := func(name string, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("version.txt", "1.0.0\n")
+	write("bump.md", "bump\n")
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	for _, name := range []string{"version.txt", "bump.md"} {
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("git add: %v", err)
+		}
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	if _, err := worktree.Commit("initial commit", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	before, err := WorktreeChanges(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(before) != 0 {
+		t.Fatalf("changes before = %v, want a clean worktree", before)
+	}
+
+	write("version.txt", "1.1.0\n")
+	write("CHANGELOG.md", "# Changelog\n")
+	if err := os.Remove(filepath.Join(dir, "bump.md")); err != nil {
+		t.Fatalf("remove bump: %v", err)
+	}
+
+	after, err := WorktreeChanges(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	touched := TouchedFiles(before, after)
+	if want := []string{"CHANGELOG.md", "bump.md", "version.txt"}; !reflect.DeepEqual(touched, want) {
+		t.Fatalf("touched = %v, want %v", touched, want)
+	}
+
+	if _, err := CommitFiles(dir, touched, "chore: release\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	remaining, err := WorktreeChanges(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("changes after commit = %v, want a clean worktree", remaining)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("get HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("read commit: %v", err)
+	}
+	if commit.Message != "chore: release\n" {
+		t.Errorf("message = %q, want %q", commit.Message, "chore: release\n")
+	}
+	if _, err := commit.File("bump.md"); err == nil {
+		t.Error("expected bump.md to be removed by the commit")
+	}
+}
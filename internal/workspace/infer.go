@@ -0,0 +1,300 @@
+package workspace
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// HistoryCommit is a commit considered for bump inference.
+type HistoryCommit struct {
+	SHA         string
+	When        time.Time
+	AuthorName  string
+	AuthorEmail string
+	Message     string
+}
+
+// CommitHistory lists the commits made since a release group's last release.
+type CommitHistory interface {
+	CommitsSince(ctx context.Context, group ReleaseGroup) ([]HistoryCommit, error)
+}
+
+// FakeCommitHistory serves canned commits per group name so callers can be
+// tested without a git repository.
+type FakeCommitHistory struct {
+	Commits map[string][]HistoryCommit
+}
+
+func (f *FakeCommitHistory) CommitsSince(_ context.Context, group ReleaseGroup) ([]HistoryCommit, error) {
+	return f.Commits[group.Name], nil
+}
+
+// GitHistory reads commit history from the git repository containing Dir. A
+// group's last release is the highest tag matching its tag_format (or
+// DefaultTagFormat) reachable from HEAD. Without one, it is the last commit
+// changing the group's changelog, which bumper commit amends on every
+// release, and only a group that was never released returns the whole
+// history. A directory outside any git repository degrades to no commits with
+// a warning.
+type GitHistory struct {
+	Logger *slog.Logger
+	Dir    string
+}
+
+func NewGitHistory(logger *slog.Logger, dir string) *GitHistory {
+	return &GitHistory{Logger: logger, Dir: dir}
+}
+
+var _ CommitHistory = (*GitHistory)(nil)
+
+func (g *GitHistory) CommitsSince(ctx context.Context, group ReleaseGroup) ([]HistoryCommit, error) {
+	format := group.TagFormat
+	if format == "" {
+		format = DefaultTagFormat
+	}
+
+	latest, err := LatestTag(ctx, g.Logger, g.Dir, format)
+	switch {
+	case errors.Is(err, errNoGitRepository):
+		g.Logger.WarnContext(ctx, "git repository not found", slog.String("dir", g.Dir))
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("find last release tag: %w", err)
+	}
+
+	repo, err := openGitRepository(g.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("get HEAD: %w", err)
+	}
+
+	var boundary plumbing.Hash
+	if latest != nil {
+		boundary = latest.Commit
+	} else {
+		changelog := group.ChangelogFilename(g.Dir)
+		boundary, err = lastCommitChanging(repo, head.Hash(), changelog)
+		if err != nil {
+			return nil, err
+		}
+		if boundary.IsZero() {
+			g.Logger.WarnContext(ctx, "no release tag or changelog commit found; inferring from the whole history", slog.String("group", group.Name), slog.String("tag_format", format))
+		} else {
+			g.Logger.InfoContext(ctx, "no release tag found; inferring from the last commit changing the changelog", slog.String("group", group.Name), slog.String("tag_format", format), slog.String("file", changelog), slog.String("commit", boundary.String()))
+		}
+	}
+
+	released := make(map[plumbing.Hash]struct{})
+	if !boundary.IsZero() {
+		released, err = ancestors(repo, boundary)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	unreleased, err := ancestors(repo, head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]HistoryCommit, 0, len(unreleased))
+	for hash := range unreleased {
+		if _, ok := released[hash]; ok {
+			continue
+		}
+
+		c, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("read commit %s: %w", hash, err)
+		}
+		commits = append(commits, HistoryCommit{
+			SHA:         c.Hash.String(),
+			When:        c.Committer.When,
+			AuthorName:  c.Author.Name,
+			AuthorEmail: c.Author.Email,
+			Message:     c.Message,
+		})
+	}
+
+	return commits, nil
+}
+
+// lastCommitChanging returns the most recent commit reachable from from that
+// changed filename, or the zero hash when there is none within the fetched
+// history.
+func lastCommitChanging(repo *git.Repository, from plumbing.Hash, filename string) (plumbing.Hash, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("get worktree: %w", err)
+	}
+	rel, err := filepath.Rel(worktree.Filesystem().Root(), filename)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("get path relative to git root: %w", err)
+	}
+	rel = filepath.ToSlash(rel)
+
+	commitIter, err := repo.Log(&git.LogOptions{From: from, FileName: &rel})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("get commit log: %w", err)
+	}
+	defer commitIter.Close()
+
+	c, err := commitIter.Next()
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, plumbing.ErrObjectNotFound):
+		return plumbing.ZeroHash, nil
+	case err != nil:
+		return plumbing.ZeroHash, fmt.Errorf("iterate commits: %w", err)
+	}
+
+	return c.Hash, nil
+}
+
+// ancestors returns from and every commit reachable from it within the
+// fetched history.
+func ancestors(repo *git.Repository, from plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	commitIter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, fmt.Errorf("get commit log: %w", err)
+	}
+	defer commitIter.Close()
+
+	result := make(map[plumbing.Hash]struct{})
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		result[c.Hash] = struct{}{}
+		return nil
+	})
+	switch {
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		// The walk reached a shallow-clone boundary.
+	case err != nil:
+		return nil, fmt.Errorf("iterate commits: %w", err)
+	}
+
+	return result, nil
+}
+
+// ConventionalCommit is the parsed header and breaking-change footer of a
+// commit message following the Conventional Commits specification.
+type ConventionalCommit struct {
+	Type        string
+	Scopes      []string
+	Breaking    bool
+	Description string
+}
+
+var conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// ParseConventionalCommit parses message, reporting false when its first
+// line is not a conventional commit header. A scope list may name several
+// comma-separated scopes.
+func ParseConventionalCommit(message string) (ConventionalCommit, bool) {
+	header, body, _ := strings.Cut(message, "\n")
+	m := conventionalHeader.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return ConventionalCommit{}, false
+	}
+
+	cc := ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Breaking:    m[3] == "!",
+		Description: strings.TrimSpace(m[4]),
+	}
+	for scope := range strings.SplitSeq(m[2], ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			cc.Scopes = append(cc.Scopes, scope)
+		}
+	}
+
+	for line := range strings.SplitSeq(body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			cc.Breaking = true
+		}
+	}
+
+	return cc, true
+}
+
+// Level maps the commit to a bump: breaking changes are major, features
+// minor and fixes patch. Other types imply no release.
+func (c ConventionalCommit) Level() BumpLevel {
+	switch {
+	case c.Breaking:
+		return BumpLevelMajor
+	case c.Type == "feat":
+		return BumpLevelMinor
+	case c.Type == "fix":
+		return BumpLevelPatch
+	default:
+		return BumpLevelNone
+	}
+}
+
+// InferBumps turns the conventional commits made since each group's last
+// release into synthetic bumps, one per commit, ready for SquashBumps. A
+// commit applies to the groups matching one of its scopes; unscoped commits
+// apply only when the config has a single group. The bumps have no File and
+// are ordered by commit time.
+func InferBumps(ctx context.Context, logger *slog.Logger, history CommitHistory, cfg *Config) ([]ParsedBump, error) {
+	bySHA := make(map[string]*ParsedBump)
+	for _, group := range cfg.Groups {
+		commits, err := history.CommitsSince(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("release group %s: %w", group.Name, err)
+		}
+
+		for _, commit := range commits {
+			cc, ok := ParseConventionalCommit(commit.Message)
+			if !ok || cc.Level() == BumpLevelNone {
+				continue
+			}
+
+			applies := len(cc.Scopes) == 0 && len(cfg.Groups) == 1
+			for _, scope := range cc.Scopes {
+				applies = applies || group.MatchesScope(scope)
+			}
+			if !applies {
+				continue
+			}
+
+			bump, ok := bySHA[commit.SHA]
+			if !ok {
+				bump = &ParsedBump{
+					Levels:  map[string]string{},
+					Message: cc.Description,
+					Commit:  &Commit{SHA: commit.SHA, When: commit.When, AuthorName: commit.AuthorName, AuthorEmail: commit.AuthorEmail},
+				}
+				bySHA[commit.SHA] = bump
+			}
+			bump.Levels[group.Name] = cc.Level().String()
+			logger.DebugContext(ctx, "inferred bump from commit", slog.String("group", group.Name), slog.String("commit", commit.SHA), slog.String("level", cc.Level().String()))
+		}
+	}
+
+	bumps := make([]ParsedBump, 0, len(bySHA))
+	for _, bump := range bySHA {
+		bumps = append(bumps, *bump)
+	}
+	slices.SortFunc(bumps, func(a, b ParsedBump) int {
+		return cmp.Or(a.Commit.When.Compare(b.Commit.When), strings.Compare(a.Commit.SHA, b.Commit.SHA))
+	})
+
+	return bumps, nil
+}
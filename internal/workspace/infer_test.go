@@ -0,0 +1,227 @@
+package workspace
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    ConventionalCommit
+		wantOK  bool
+		level   BumpLevel
+	}{
+		{
+			name:    "feature",
+			message: "feat: add search\n",
+			want:    ConventionalCommit{Type: "feat", Description: "add search"},
+			wantOK:  true,
+			level:   BumpLevelMinor,
+		},
+		{
+			name:    "scoped fix",
+			message: "fix(api): handle empty token",
+			want:    ConventionalCommit{Type: "fix", Scopes: []string{"api"}, Description: "handle empty token"},
+			wantOK:  true,
+			level:   BumpLevelPatch,
+		},
+		{
+			name:    "several scopes",
+			message: "fix(api, web): shared fix",
+			want:    ConventionalCommit{Type: "fix", Scopes: []string{"api", "web"}, Description: "shared fix"},
+			wantOK:  true,
+			level:   BumpLevelPatch,
+		},
+		{
+			name:    "breaking marker",
+			message: "refactor(api)!: drop v1 routes",
+			want:    ConventionalCommit{Type: "refactor", Scopes: []string{"api"}, Breaking: true, Description: "drop v1 routes"},
+			wantOK:  true,
+			level:   BumpLevelMajor,
+		},
+		{
+			name:    "breaking footer",
+			message: "feat: new config format\n\nBREAKING CHANGE: the old format is gone\n",
+			want:    ConventionalCommit{Type: "feat", Breaking: true, Description: "new config format"},
+			wantOK:  true,
+			level:   BumpLevelMajor,
+		},
+		{
+			name:    "no release",
+			message: "chore: update deps",
+			want:    ConventionalCommit{Type: "chore", Description: "update deps"},
+			wantOK:  true,
+			level:   BumpLevelNone,
+		},
+		{
+			name:    "not conventional",
+			message: "Merge pull request #12 from feature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseConventionalCommit(tt.message)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commit = %#v, want %#v", got, tt.want)
+			}
+			if got.Level() != tt.level {
+				t.Errorf("level = %v, want %v", got.Level(), tt.level)
+			}
+		})
+	}
+}
+
+func TestInferBumps(t *testing.T) {
+	api := validGroup("api")
+	api.Scopes = []string{"server"}
+	cfg := &Config{Groups: []ReleaseGroup{api, validGroup("web")}}
+
+	shared := HistoryCommit{SHA: "aaa1111", When: time.Unix(1700000100, 0), Message: "fix(server,web): shared fix"}
+	history := &FakeCommitHistory{Commits: map[string][]HistoryCommit{
+		"api": {
+			{SHA: "bbb2222", When: time.Unix(1700000200, 0), Message: "feat(api): new endpoint"},
+			shared,
+			{SHA: "ccc3333", When: time.Unix(1700000300, 0), Message: "feat: unscoped"},
+			{SHA: "ddd4444", When: time.Unix(1700000400, 0), Message: "docs(api): typo"},
+		},
+		"web": {shared},
+	}}
+	logger := slog.New(slog.DiscardHandler)
+
+	bumps, err := InferBumps(t.Context(), logger, history, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []ParsedBump{
+		{
+			Levels:  map[string]string{"api": "patch", "web": "patch"},
+			Message: "shared fix",
+			Commit:  &Commit{SHA: "aaa1111", When: time.Unix(1700000100, 0)},
+		},
+		{
+			Levels:  map[string]string{"api": "minor"},
+			Message: "new endpoint",
+			Commit:  &Commit{SHA: "bbb2222", When: time.Unix(1700000200, 0)},
+		},
+	}
+	if !reflect.DeepEqual(bumps, want) {
+		t.Errorf("bumps = %#v, want %#v", bumps, want)
+	}
+
+	statuses := SquashBumps(t.Context(), logger, bumps, cfg)
+	if got := statuses["api"].Level; got != BumpLevelMinor {
+		t.Errorf("api level = %v, want %v", got, BumpLevelMinor)
+	}
+}
+
+func TestGitHistorySinceLastTag(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+
+	when := time.Unix(1700000000, 0)
+	commit := func(msg string) string {
+		t.Helper()
+		when = when.Add(time.Minute)
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: when}
+		hash, err := worktree.Commit(msg, &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+		return hash.String()
+	}
+
+	commit("feat(api): released feature")
+	released := commit("fix(api): released fix")
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("get HEAD: %v", err)
+	}
+	if _, err := repo.CreateTag("api/v1.0.0", head.Hash(), nil); err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	pending := commit("feat(api): pending feature")
+
+	group := validGroup("api")
+	group.TagFormat = "api/v{{version}}"
+	logger := slog.New(slog.DiscardHandler)
+
+	commits, err := NewGitHistory(logger, dir).CommitsSince(t.Context(), group)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 1 || commits[0].SHA != pending {
+		t.Errorf("commits = %#v, want only %s after the tag at %s", commits, pending, released)
+	}
+
+	// Without a matching tag, the whole history is unreleased.
+	group.TagFormat = "web/v{{version}}"
+	commits, err = NewGitHistory(logger, dir).CommitsSince(t.Context(), group)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Errorf("commits = %d, want all 3", len(commits))
+	}
+}
+
+// Without a release tag, the last commit changing the changelog marks the
+// last release.
+func TestGitHistorySinceLastChangelogCommit(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+
+	when := time.Unix(1700000000, 0)
+	commit := func(msg string, changelog string) string {
+		t.Helper()
+		if changelog != "" {
+			if err := os.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte(changelog), 0o644); err != nil {
+				t.Fatalf("write changelog: %v", err)
+			}
+			if _, err := worktree.Add("CHANGELOG.md"); err != nil {
+				t.Fatalf("git add: %v", err)
+			}
+		}
+		when = when.Add(time.Minute)
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: when}
+		hash, err := worktree.Commit(msg, &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+		return hash.String()
+	}
+
+	commit("feat(api): released feature", "")
+	commit("chore: release", "# Changelog\n\n## api 1.0.0\n")
+	pending := commit("fix(api): pending fix", "")
+
+	logger := slog.New(slog.DiscardHandler)
+	commits, err := NewGitHistory(logger, dir).CommitsSince(t.Context(), validGroup("api"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(commits) != 1 || commits[0].SHA != pending {
+		t.Errorf("commits = %#v, want only %s after the release commit", commits, pending)
+	}
+}
@@ -0,0 +1,193 @@
+package workspace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// LockEnv names the environment variable through which a bumper process
+// holding the workspace lock shares it with the commands it runs, e.g. the
+// next:* builtins run by `bumper commit`.
+const LockEnv = "BUMPER_LOCK"
+
+// DefaultLockTimeout is how long to wait for another process to release the
+// workspace lock.
+const DefaultLockTimeout = 30 * time.Second
+
+// ErrLocked is returned when the workspace lock is still held by another
+// process once the timeout expires.
+var ErrLocked = errors.New("workspace is locked by another bumper process")
+
+// lockPollInterval is how often a waiting process checks the lock.
+const lockPollInterval = 100 * time.Millisecond
+
+func LockFilename(base string) string {
+	return filepath.Join(Dir(base), "lock")
+}
+
+// isLockPath reports whether p, a slash-separated path relative to a git
+// repository root, is a workspace lock file. Lock files are never part of a
+// release.
+func isLockPath(p string) bool {
+	return path.Base(p) == "lock" && path.Base(path.Dir(p)) == ".bumper"
+}
+
+// lockInfo is the content of the lock file.
+type lockInfo struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Token    string    `json:"token"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// Lock is an advisory lock on a workspace, held by creating the lock file in
+// .bumper. It only excludes other bumper processes.
+type Lock struct {
+	filename string
+	token    string
+	// inherited marks a lock held by a parent bumper process, which
+	// releases it.
+	inherited bool
+}
+
+// AcquireLock takes the lock on the workspace at dir, waiting up to timeout
+// for another process to release it. A lock left behind by a process that no
+// longer runs on this host is removed. When LockEnv, looked up in the
+// Environ of ctx, names the current lock, the lock is already held by a
+// parent bumper process and is shared.
+func AcquireLock(ctx context.Context, dir string, timeout time.Duration) (*Lock, error) {
+	filename := LockFilename(dir)
+
+	if token, _ := EnvironFrom(ctx).LookupEnv(LockEnv); token != "" {
+		if info, err := readLock(filename); err == nil && info.Token == token {
+			return &Lock{filename: filename, token: token, inherited: true}, nil
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("get hostname: %w", err)
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("generate lock token: %w", err)
+	}
+	info := lockInfo{PID: os.Getpid(), Hostname: hostname, Token: hex.EncodeToString(b[:]), Acquired: time.Now().UTC()}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("encode lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := createFileAtomic(filename, append(data, '\n'), 0644)
+		if err == nil {
+			return &Lock{filename: filename, token: info.Token}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+
+		holder, err := readLock(filename)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// Released in the meantime.
+			continue
+		case err != nil:
+			return nil, err
+		case holder.Hostname == hostname && !processRunning(holder.PID):
+			if err := removeStaleLock(filename, holder.Token); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: process %d on %s holds %s since %s", ErrLocked, holder.PID, holder.Hostname, filename, holder.Acquired.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(lockPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// Env returns the LockEnv entry sharing the lock with child processes.
+func (l *Lock) Env() string {
+	return LockEnv + "=" + l.token
+}
+
+// Release removes the lock file, unless the lock is shared by a parent
+// process or has been taken over since.
+func (l *Lock) Release() error {
+	if l.inherited {
+		return nil
+	}
+
+	info, err := readLock(l.filename)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case info.Token != l.token:
+		return nil
+	}
+
+	if err := os.Remove(l.filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove lock file: %w", err)
+	}
+
+	return nil
+}
+
+func readLock(filename string) (*lockInfo, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read lock file: %w", err)
+	}
+
+	var info lockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("decode lock file %s: %w", filename, err)
+	}
+
+	return &info, nil
+}
+
+// removeStaleLock removes the lock file if it still holds the stale lock
+// identified by token. The file is first moved aside, so a process that
+// took the lock over since keeps it.
+func removeStaleLock(filename string, token string) error {
+	aside := fmt.Sprintf("%s.stale-%d", filename, os.Getpid())
+	if err := os.Rename(filename, aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("move stale lock file: %w", err)
+	}
+
+	info, err := readLock(aside)
+	if err == nil && info.Token != token {
+		// A live lock was moved aside: put it back.
+		if err := os.Link(aside, filename); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("restore lock file: %w", err)
+		}
+	}
+
+	if err := os.Remove(aside); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove stale lock file: %w", err)
+	}
+
+	return nil
+}
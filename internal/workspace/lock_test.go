@@ -0,0 +1,112 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	dir := setupBumperDir(t)
+
+	lock, err := AcquireLock(t.Context(), dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := AcquireLock(t.Context(), dir, 0); !errors.Is(err, ErrLocked) {
+		t.Errorf("second acquire error = %v, want ErrLocked", err)
+	}
+
+	// A parent holding the lock shares it through the environment.
+	t.Setenv(LockEnv, strings.TrimPrefix(lock.Env(), LockEnv+"="))
+	shared, err := AcquireLock(t.Context(), dir, 0)
+	if err != nil {
+		t.Fatalf("acquire shared lock: %v", err)
+	}
+	if err := shared.Release(); err != nil {
+		t.Fatalf("release shared lock: %v", err)
+	}
+	if _, err := os.Stat(LockFilename(dir)); err != nil {
+		t.Errorf("lock file stat error = %v, want the parent's lock kept", err)
+	}
+	t.Setenv(LockEnv, "")
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(LockFilename(dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file stat error = %v, want the lock file removed", err)
+	}
+
+	lock, err = AcquireLock(t.Context(), dir, 0)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAcquireLockWaits(t *testing.T) {
+	dir := setupBumperDir(t)
+
+	held, err := AcquireLock(t.Context(), dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.AfterFunc(3*lockPollInterval, func() { _ = held.Release() })
+
+	lock, err := AcquireLock(t.Context(), dir, 10*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAcquireLockRemovesStaleLock(t *testing.T) {
+	dir := setupBumperDir(t)
+
+	// A process that has exited leaves its lock behind.
+	proc := exec.Command(os.Args[0], "-test.run=^$")
+	if err := proc.Run(); err != nil {
+		t.Fatalf("run process: %v", err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("get hostname: %v", err)
+	}
+	data, err := json.Marshal(lockInfo{PID: proc.Process.Pid, Hostname: hostname, Token: "stale"})
+	if err != nil {
+		t.Fatalf("encode lock: %v", err)
+	}
+	if err := os.WriteFile(LockFilename(dir), data, 0o644); err != nil {
+		t.Fatalf("write lock file: %v", err)
+	}
+
+	lock, err := AcquireLock(t.Context(), dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A lock held on another host is never considered stale.
+	data, err = json.Marshal(lockInfo{PID: proc.Process.Pid, Hostname: hostname + "-elsewhere", Token: "remote"})
+	if err != nil {
+		t.Fatalf("encode lock: %v", err)
+	}
+	if err := os.WriteFile(LockFilename(dir), data, 0o644); err != nil {
+		t.Fatalf("write lock file: %v", err)
+	}
+	if _, err := AcquireLock(t.Context(), dir, 0); !errors.Is(err, ErrLocked) {
+		t.Errorf("error = %v, want ErrLocked for another host's lock", err)
+	}
+}
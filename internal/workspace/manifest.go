@@ -0,0 +1,57 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ReleaseManifest summarizes a `bumper commit` run for downstream tooling. It
+// is written as JSON by `bumper commit --manifest`.
+type ReleaseManifest struct {
+	Releases []ReleaseRecord `json:"releases"`
+}
+
+// ReleaseRecord is one released group in a ReleaseManifest.
+type ReleaseRecord struct {
+	Group       string `json:"group"`
+	DisplayName string `json:"display_name,omitempty"`
+	// PreviousVersion is the version before the release, recorded in the
+	// commit checkpoint so retries keep it.
+	PreviousVersion string    `json:"previous_version,omitempty"`
+	Version         string    `json:"version"`
+	Level           BumpLevel `json:"level"`
+	// Changelog is the group's changelog section for Version, as printed by
+	// its cat command.
+	Changelog string `json:"changelog"`
+	// Commits are the full hashes of the commits that added the bumps
+	// released, oldest first.
+	Commits []string `json:"commits"`
+}
+
+func WriteReleaseManifest(path string, manifest *ReleaseManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode release manifest: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write release manifest: %w", err)
+	}
+
+	return nil
+}
+
+func LoadReleaseManifest(path string) (*ReleaseManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read release manifest: %w", err)
+	}
+
+	var manifest ReleaseManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("decode release manifest %s: %w", path, err)
+	}
+
+	return &manifest, nil
+}
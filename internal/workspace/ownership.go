@@ -0,0 +1,198 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// OwnsPath reports whether the group owns the file at rel, a slash-separated
+// path relative to the workspace root. A file is owned when it matches one of
+// the group's path globs and none of the globs prefixed with "!". Globs use
+// path.Match syntax per segment, and a "**" segment matches any number of
+// segments.
+func (g ReleaseGroup) OwnsPath(rel string) bool {
+	owned := false
+	for _, pattern := range g.Paths {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchPathGlob(negated, rel) {
+				return false
+			}
+			continue
+		}
+
+		owned = owned || matchPathGlob(pattern, rel)
+	}
+
+	return owned
+}
+
+func validatePathGlob(pattern string) error {
+	pattern = strings.TrimPrefix(pattern, "!")
+	if pattern == "" {
+		return errors.New("empty path glob")
+	}
+
+	for segment := range strings.SplitSeq(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid path glob %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func matchPathGlob(pattern string, rel string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	ok, err := path.Match(pattern[0], segments[0])
+	return err == nil && ok && matchSegments(pattern[1:], segments[1:])
+}
+
+// TouchedGroups maps the release groups owning at least one of files to the
+// files they own, in config order.
+func TouchedGroups(cfg *Config, files []string) map[string][]string {
+	touched := make(map[string][]string)
+	for _, group := range cfg.Groups {
+		for _, file := range files {
+			if group.OwnsPath(file) {
+				touched[group.Name] = append(touched[group.Name], file)
+			}
+		}
+	}
+
+	return touched
+}
+
+// ChangedFiles lists the files changed on HEAD since it diverged from base, a
+// git revision such as "origin/main", in the git repository containing dir.
+// Like `git diff base...HEAD`, it compares HEAD with the merge base, so
+// changes landing on base in the meantime are not included. Paths are
+// slash-separated, relative to dir and sorted; files outside dir are
+// omitted. Shallow clones are deepened until the merge base is fetched.
+func ChangedFiles(ctx context.Context, dir string, base string) ([]string, error) {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+	gitRoot := worktree.Filesystem().Root()
+
+	prefix, err := filepath.Rel(gitRoot, dir)
+	if err != nil {
+		return nil, fmt.Errorf("get path relative to git root: %w", err)
+	}
+	prefix = filepath.ToSlash(prefix)
+
+	baseHash, err := repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return nil, fmt.Errorf("resolve base revision %s: %w", base, err)
+	}
+
+	for range 10 {
+		baseCommit, err := repo.CommitObject(*baseHash)
+		if err != nil {
+			return nil, fmt.Errorf("read base commit: %w", err)
+		}
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("get HEAD: %w", err)
+		}
+		headCommit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("read HEAD commit: %w", err)
+		}
+
+		mergeBases, err := headCommit.MergeBase(baseCommit)
+		if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, fmt.Errorf("find merge base with %s: %w", base, err)
+		}
+
+		if len(mergeBases) > 0 {
+			return diffCommits(mergeBases[0], headCommit, prefix)
+		}
+
+		isShallow, err := isShallowRepo(repo)
+		if err != nil {
+			return nil, fmt.Errorf("check if repo is shallow: %w", err)
+		}
+		if !isShallow {
+			return nil, fmt.Errorf("HEAD and %s have no common history", base)
+		}
+
+		repo, err = deepenShallowRepo(ctx, gitRoot, 50)
+		if err != nil {
+			return nil, fmt.Errorf("deepen shallow repo: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("no merge base with %s found in the fetched history", base)
+}
+
+// diffCommits lists the files differing between two commits, relative to
+// prefix.
+func diffCommits(from *object.Commit, to *object.Commit, prefix string) ([]string, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("get commit tree: %w", err)
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("get commit tree: %w", err)
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("diff trees: %w", err)
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name == "" {
+				continue
+			}
+			if prefix != "." {
+				rel, ok := strings.CutPrefix(name, prefix+"/")
+				if !ok {
+					continue
+				}
+				name = rel
+			}
+			files = append(files, name)
+		}
+	}
+	slices.Sort(files)
+
+	return slices.Compact(files), nil
+}
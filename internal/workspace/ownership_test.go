@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestOwnsPath(t *testing.T) {
+	group := ReleaseGroup{Name: "sdk", Paths: []string{"packages/sdk/**", "!packages/sdk/**/*_test.go", "go.mod"}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "packages/sdk/client.go", want: true},
+		{path: "packages/sdk/internal/deep/file.go", want: true},
+		{path: "packages/sdk/internal/file_test.go", want: false},
+		{path: "packages/sdk", want: true},
+		{path: "packages/sdk-extra/file.go", want: false},
+		{path: "go.mod", want: true},
+		{path: "cmd/go.mod", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := group.OwnsPath(tt.path); got != tt.want {
+				t.Errorf("OwnsPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigPaths(t *testing.T) {
+	group := validGroup("sdk")
+	group.Paths = []string{"packages/[sdk/**"}
+
+	if err := validateConfig(&Config{Groups: []ReleaseGroup{group}}); err == nil {
+		t.Fatal("expected an error for a malformed path glob")
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+
+	when := time.Unix(1700000000, 0)
+	commitFile := func(name string, content string) {
+		t.Helper()
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("git add: %v", err)
+		}
+		when = when.Add(time.Minute)
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: when}
+		if _, err := worktree.Commit("change "+name, &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+	}
+
+	commitFile("packages/sdk/client.go", "package sdk\n")
+	main, err := repo.Head()
+	if err != nil {
+		t.Fatalf("get HEAD: %v", err)
+	}
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatalf("checkout feature: %v", err)
+	}
+	commitFile("packages/sdk/client.go", "package sdk\n\n// changed\n")
+	commitFile(".bumper/bump-feature.md", "---\nsdk: patch\n---\n\nfix\n")
+
+	// Changes landing on main after the fork point are not the branch's.
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: main.Name()}); err != nil {
+		t.Fatalf("checkout %s: %v", main.Name(), err)
+	}
+	commitFile("packages/web/app.go", "package web\n")
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature")}); err != nil {
+		t.Fatalf("checkout feature: %v", err)
+	}
+
+	files, err := ChangedFiles(t.Context(), dir, main.Name().Short())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{".bumper/bump-feature.md", "packages/sdk/client.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
@@ -0,0 +1,175 @@
+package workspace
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver/v3"
+)
+
+// PreMode is the stage a release group's prerelease channel is in.
+type PreMode string
+
+const (
+	// PreModeActive releases every commit as the next prerelease on the
+	// group's channel, e.g. 2.0.0-rc.1, 2.0.0-rc.2.
+	PreModeActive PreMode = "pre"
+	// PreModeExit graduates the group on the next commit: it releases the
+	// final version with every entry accumulated by its prereleases.
+	PreModeExit PreMode = "exit"
+)
+
+// preTagPattern restricts channel tags to a single semver prerelease
+// identifier so the counter can be appended after a dot.
+var preTagPattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// PreReleaseGroup is the prerelease channel of one release group.
+type PreReleaseGroup struct {
+	Mode PreMode `toml:"mode"`
+	Tag  string  `toml:"tag"`
+	// Base is the version the group was at when it entered prerelease mode.
+	// Prerelease versions are computed from it rather than from the previous
+	// prerelease so that, for instance, a minor bump after 2.0.0-rc.1 does
+	// not move the target to 2.1.0.
+	Base string `toml:"base"`
+	// Level is the highest bump level released on the channel so far.
+	Level     BumpLevel  `toml:"level"`
+	MajorLogs []LogEntry `toml:"major,omitempty"`
+	MinorLogs []LogEntry `toml:"minor,omitempty"`
+	PatchLogs []LogEntry `toml:"patch,omitempty"`
+}
+
+// PreState records which release groups are in prerelease mode. It lives in
+// .bumper/pre.toml while at least one group is on a prerelease channel.
+type PreState struct {
+	Groups map[string]*PreReleaseGroup `toml:"groups"`
+}
+
+func PreStateFilename(base string) string {
+	return filepath.Join(Dir(base), "pre.toml")
+}
+
+// ValidatePreTag reports whether tag can name a prerelease channel.
+func ValidatePreTag(tag string) error {
+	if !preTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid prerelease tag %q: must be a single alphanumeric identifier (e.g. alpha, beta, rc)", tag)
+	}
+
+	return nil
+}
+
+// NextVersion computes the version the next commit releases given the
+// group's current version and the level of its pending bumps.
+func (p *PreReleaseGroup) NextVersion(current *semver.Version, level BumpLevel) (string, error) {
+	base, err := semver.NewVersion(p.Base)
+	if err != nil {
+		return "", fmt.Errorf("%s: parse prerelease base version: %w", p.Base, err)
+	}
+
+	target, err := incVersion(base, max(p.Level, level))
+	if err != nil {
+		return "", err
+	}
+
+	if p.Mode == PreModeExit {
+		return target.String(), nil
+	}
+
+	counter := 1
+	prefix := p.Tag + "."
+	if pre := current.Prerelease(); strings.HasPrefix(pre, prefix) && sameCore(current, target) {
+		if n, err := strconv.Atoi(strings.TrimPrefix(pre, prefix)); err == nil {
+			counter = n + 1
+		}
+	}
+
+	next, err := target.SetPrerelease(fmt.Sprintf("%s%d", prefix, counter))
+	if err != nil {
+		return "", fmt.Errorf("set prerelease: %w", err)
+	}
+
+	return next.String(), nil
+}
+
+// Record accumulates a released prerelease's bumps so the graduating release
+// can include them.
+func (p *PreReleaseGroup) Record(status *ReleaseGroupStatus) {
+	p.Level = max(p.Level, status.Level)
+	p.MajorLogs = append(p.MajorLogs, status.MajorLogs...)
+	p.MinorLogs = append(p.MinorLogs, status.MinorLogs...)
+	p.PatchLogs = append(p.PatchLogs, status.PatchLogs...)
+}
+
+// GraduationStatus merges the entries accumulated by earlier prereleases
+// ahead of the pending ones, which may be nil when the group has no pending
+// bumps of its own.
+func (p *PreReleaseGroup) GraduationStatus(pending *ReleaseGroupStatus) *ReleaseGroupStatus {
+	status := &ReleaseGroupStatus{
+		Level:     p.Level,
+		MajorLogs: append([]LogEntry{}, p.MajorLogs...),
+		MinorLogs: append([]LogEntry{}, p.MinorLogs...),
+		PatchLogs: append([]LogEntry{}, p.PatchLogs...),
+	}
+
+	if pending != nil {
+		status.Level = max(status.Level, pending.Level)
+		status.MajorLogs = append(status.MajorLogs, pending.MajorLogs...)
+		status.MinorLogs = append(status.MinorLogs, pending.MinorLogs...)
+		status.PatchLogs = append(status.PatchLogs, pending.PatchLogs...)
+	}
+
+	return status
+}
+
+func sameCore(a, b *semver.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor() && a.Patch() == b.Patch()
+}
+
+// LoadPreState reads the prerelease state. It returns an empty state without
+// error when no group is in prerelease mode.
+func LoadPreState(dir string) (*PreState, error) {
+	state := PreState{Groups: map[string]*PreReleaseGroup{}}
+	_, err := toml.DecodeFile(PreStateFilename(dir), &state)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return &state, nil
+	case err != nil:
+		return nil, fmt.Errorf("decode prerelease state: %w", err)
+	}
+
+	if state.Groups == nil {
+		state.Groups = map[string]*PreReleaseGroup{}
+	}
+
+	return &state, nil
+}
+
+// SavePreState writes the prerelease state, removing the file once no group
+// remains in prerelease mode.
+func SavePreState(dir string, state *PreState) error {
+	if len(state.Groups) == 0 {
+		err := os.Remove(PreStateFilename(dir))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove prerelease state: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(state); err != nil {
+		return fmt.Errorf("encode prerelease state: %w", err)
+	}
+
+	if err := WriteFileAtomic(PreStateFilename(dir), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write prerelease state: %w", err)
+	}
+
+	return nil
+}
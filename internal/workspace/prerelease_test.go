@@ -0,0 +1,167 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestPreReleaseGroupNextVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		pre     PreReleaseGroup
+		current string
+		level   BumpLevel
+		want    string
+	}{
+		{
+			name:    "first prerelease on the channel",
+			pre:     PreReleaseGroup{Mode: PreModeActive, Tag: "rc", Base: "1.4.2"},
+			current: "1.4.2",
+			level:   BumpLevelMajor,
+			want:    "2.0.0-rc.1",
+		},
+		{
+			name:    "subsequent prerelease increments the counter",
+			pre:     PreReleaseGroup{Mode: PreModeActive, Tag: "rc", Base: "1.4.2", Level: BumpLevelMajor},
+			current: "2.0.0-rc.1",
+			level:   BumpLevelPatch,
+			want:    "2.0.0-rc.2",
+		},
+		{
+			name:    "lower pending level keeps the accumulated target",
+			pre:     PreReleaseGroup{Mode: PreModeActive, Tag: "beta", Base: "1.4.2", Level: BumpLevelMinor},
+			current: "1.5.0-beta.3",
+			level:   BumpLevelPatch,
+			want:    "1.5.0-beta.4",
+		},
+		{
+			name:    "higher pending level moves the target and restarts the counter",
+			pre:     PreReleaseGroup{Mode: PreModeActive, Tag: "beta", Base: "1.4.2", Level: BumpLevelMinor},
+			current: "1.5.0-beta.3",
+			level:   BumpLevelMajor,
+			want:    "2.0.0-beta.1",
+		},
+		{
+			name:    "switching channels restarts the counter",
+			pre:     PreReleaseGroup{Mode: PreModeActive, Tag: "rc", Base: "1.4.2", Level: BumpLevelMajor},
+			current: "2.0.0-beta.5",
+			level:   BumpLevelPatch,
+			want:    "2.0.0-rc.1",
+		},
+		{
+			name:    "graduation releases the final version",
+			pre:     PreReleaseGroup{Mode: PreModeExit, Tag: "rc", Base: "1.4.2", Level: BumpLevelMajor},
+			current: "2.0.0-rc.2",
+			level:   BumpLevelNone,
+			want:    "2.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pre.NextVersion(semver.MustParse(tt.current), tt.level)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("next version = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("nothing to release", func(t *testing.T) {
+		pre := PreReleaseGroup{Mode: PreModeActive, Tag: "rc", Base: "1.4.2"}
+		if _, err := pre.NextVersion(semver.MustParse("1.4.2"), BumpLevelNone); err == nil {
+			t.Error("expected error without any bump level")
+		}
+	})
+}
+
+func TestPreReleaseGroupGraduationStatus(t *testing.T) {
+	pre := &PreReleaseGroup{Mode: PreModeExit, Tag: "rc", Base: "1.0.0"}
+	pre.Record(&ReleaseGroupStatus{
+		Level:     BumpLevelMajor,
+		MajorLogs: []LogEntry{{Content: "breaking"}},
+	})
+	pre.Record(&ReleaseGroupStatus{
+		Level:     BumpLevelPatch,
+		PatchLogs: []LogEntry{{Content: "fix in rc.2"}},
+	})
+
+	got := pre.GraduationStatus(&ReleaseGroupStatus{
+		Level:     BumpLevelPatch,
+		PatchLogs: []LogEntry{{Content: "fix after rc.2"}},
+	})
+
+	want := &ReleaseGroupStatus{
+		Level:     BumpLevelMajor,
+		MajorLogs: []LogEntry{{Content: "breaking"}},
+		MinorLogs: []LogEntry{},
+		PatchLogs: []LogEntry{{Content: "fix in rc.2"}, {Content: "fix after rc.2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("status = %#v, want %#v", got, want)
+	}
+
+	if nothingPending := pre.GraduationStatus(nil); nothingPending.Level != BumpLevelMajor {
+		t.Errorf("level without pending bumps = %v, want %v", nothingPending.Level, BumpLevelMajor)
+	}
+}
+
+func TestPreStateRoundTrip(t *testing.T) {
+	dir := setupBumperDir(t)
+
+	loaded, err := LoadPreState(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Groups) != 0 {
+		t.Fatalf("groups = %#v, want none when no state exists", loaded.Groups)
+	}
+
+	saved := &PreState{Groups: map[string]*PreReleaseGroup{
+		"api": {
+			Mode:      PreModeActive,
+			Tag:       "rc",
+			Base:      "1.4.2",
+			Level:     BumpLevelMinor,
+			MinorLogs: []LogEntry{{Timestamp: 1, Commit: "abc1234", Content: "abc1234: feature"}},
+		},
+	}}
+	if err := SavePreState(dir, saved); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err = LoadPreState(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("loaded = %#v, want %#v", loaded, saved)
+	}
+
+	// Saving an empty state leaves prerelease mode entirely.
+	if err := SavePreState(dir, &PreState{Groups: map[string]*PreReleaseGroup{}}); err != nil {
+		t.Fatalf("save empty: %v", err)
+	}
+	if _, err := os.Stat(PreStateFilename(dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("state file stat = %v, want it removed", err)
+	}
+}
+
+func TestValidatePreTag(t *testing.T) {
+	for _, tag := range []string{"alpha", "beta", "rc", "next-1"} {
+		if err := ValidatePreTag(tag); err != nil {
+			t.Errorf("ValidatePreTag(%q) = %v, want nil", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "rc.1", "r c", "beta+1"} {
+		if err := ValidatePreTag(tag); err == nil {
+			t.Errorf("ValidatePreTag(%q) = nil, want an error", tag)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package workspace
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processRunning reports whether a process with the given PID exists.
+func processRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package workspace
+
+import "os"
+
+// processRunning reports whether a process with the given PID exists.
+// FindProcess opens a handle to the process, which fails once it is gone.
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+
+	return true
+}
@@ -7,8 +7,10 @@ import (
 
 // Commit identifies the commit that introduced a bump file.
 type Commit struct {
-	SHA  string
-	When time.Time
+	SHA         string
+	When        time.Time
+	AuthorName  string
+	AuthorEmail string
 }
 
 // Provenance resolves the commit that introduced each bump file. Files that
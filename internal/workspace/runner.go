@@ -2,12 +2,16 @@ package workspace
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"slices"
+	"strconv"
+	"strings"
 )
 
 // Verb identifies one of the group-command hooks a release group configures in
@@ -28,6 +32,9 @@ type GroupInvocation struct {
 	Verb Verb
 	Argv []string
 	Env  []string
+	// Input is the JSON ChangelogInput of a changelog command. Runners write
+	// it to a temporary file named by BUMPER_CHANGELOG_INPUT.
+	Input []byte
 }
 
 func NewCurrentInvocation(group ReleaseGroup) (GroupInvocation, error) {
@@ -44,18 +51,31 @@ func NewCurrentInvocation(group ReleaseGroup) (GroupInvocation, error) {
 	}, nil
 }
 
-func NewNextInvocation(group ReleaseGroup, nextVersion string) (GroupInvocation, error) {
+// NewNextInvocation resolves the next version command. dependencies maps the
+// released dependencies that caused the bump to their new versions; they are
+// passed as BUMPER_GROUP_DEPENDENCIES (comma-separated name=version pairs) so
+// the command can update its dependency pins.
+func NewNextInvocation(group ReleaseGroup, nextVersion string, dependencies map[string]string) (GroupInvocation, error) {
 	if len(group.NextCMD) == 0 {
 		return GroupInvocation{}, errors.New("no next version command defined for release group")
 	}
 
+	env := []string{
+		fmt.Sprintf("BUMPER_GROUP=%s", group.Name),
+		fmt.Sprintf("BUMPER_GROUP_NEXT_VERSION=%s", nextVersion),
+	}
+	if len(dependencies) > 0 {
+		pairs := make([]string, 0, len(dependencies))
+		for _, name := range slices.Sorted(maps.Keys(dependencies)) {
+			pairs = append(pairs, name+"="+dependencies[name])
+		}
+		env = append(env, fmt.Sprintf("BUMPER_GROUP_DEPENDENCIES=%s", strings.Join(pairs, ",")))
+	}
+
 	return GroupInvocation{
 		Verb: VerbNext,
 		Argv: slices.Clone(group.NextCMD),
-		Env: []string{
-			fmt.Sprintf("BUMPER_GROUP=%s", group.Name),
-			fmt.Sprintf("BUMPER_GROUP_NEXT_VERSION=%s", nextVersion),
-		},
+		Env:  env,
 	}, nil
 }
 
@@ -76,6 +96,11 @@ func NewChangelogInvocation(group ReleaseGroup, nextVersion string, status *Rele
 		argv = append(argv, "--patch", entry.Content)
 	}
 
+	input, err := json.Marshal(newChangelogInput(group, nextVersion, status))
+	if err != nil {
+		return GroupInvocation{}, fmt.Errorf("encode changelog input: %w", err)
+	}
+
 	return GroupInvocation{
 		Verb: VerbChangelog,
 		Argv: argv,
@@ -83,6 +108,7 @@ func NewChangelogInvocation(group ReleaseGroup, nextVersion string, status *Rele
 			fmt.Sprintf("BUMPER_GROUP=%s", group.Name),
 			fmt.Sprintf("BUMPER_GROUP_NEXT_VERSION=%s", nextVersion),
 		},
+		Input: input,
 	}, nil
 }
 
@@ -109,16 +135,79 @@ type Runner interface {
 
 // ExecRunner runs group commands as child processes with stderr passed through
 // to the parent process.
-type ExecRunner struct{}
+type ExecRunner struct {
+	// Env is added to the environment of every command, before the
+	// invocation's own variables, e.g. the LockEnv entry of a held lock.
+	Env []string
+	// Builtin, when set, runs the commands that invoke bumper's builtins
+	// (see BuiltinArgs) in this process instead of a child process.
+	Builtin BuiltinFunc
+}
+
+func (r ExecRunner) Run(ctx context.Context, dir string, inv GroupInvocation, stdout io.Writer) error {
+	env := append(slices.Clone(r.Env), inv.Env...)
+	if inv.Input != nil {
+		path, cleanup, err := writeChangelogInput(inv.Input)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		env = append(env, ChangelogInputEnv+"="+path)
+	}
+
+	if args, ok := BuiltinArgs(inv.Argv); ok && r.Builtin != nil {
+		return r.Builtin(ctx, Environ{Dir: dir, Env: env}, args, stdout, StderrFrom(ctx))
+	}
 
-func (ExecRunner) Run(ctx context.Context, dir string, inv GroupInvocation, stdout io.Writer) error {
 	cmd := exec.CommandContext(ctx, inv.Argv[0], inv.Argv[1:]...)
 	cmd.Dir = dir
-	cmd.Env = append(os.Environ(), inv.Env...)
+	cmd.Env = append(os.Environ(), env...)
 	cmd.Stdout = stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = StderrFrom(ctx)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
 
-	return cmd.Run()
+	return cmd.Wait()
+}
+
+type stderrKey struct{}
+
+// WithStderr returns a context in which ExecRunner passes the standard error
+// of commands to w instead of the parent's, e.g. to buffer the output of
+// groups released concurrently.
+func WithStderr(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, stderrKey{}, w)
+}
+
+// StderrFrom returns the writer set by WithStderr, or os.Stderr.
+func StderrFrom(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(stderrKey{}).(io.Writer); ok {
+		return w
+	}
+
+	return os.Stderr
+}
+
+// writeChangelogInput writes input to a temporary file, returning its path
+// and a function removing it.
+func writeChangelogInput(input []byte) (string, func(), error) {
+	f, err := os.CreateTemp("", "bumper-changelog-*.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("create changelog input file: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(f.Name()) }
+
+	_, err = f.Write(input)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("write changelog input file: %w", err)
+	}
+
+	return f.Name(), cleanup, nil
 }
 
 // FakeRunner records every invocation and plays back canned stdout per verb so
@@ -148,3 +237,40 @@ func (f *FakeRunner) Run(_ context.Context, dir string, inv GroupInvocation, std
 
 	return nil
 }
+
+// RecordingRunner previews a release: it passes current-version commands
+// through to Runner, since they only read, and records every other invocation
+// instead of running it, describing each one to Out as it goes.
+type RecordingRunner struct {
+	Runner Runner
+	Out    io.Writer
+	Calls  []GroupInvocation
+}
+
+func (r *RecordingRunner) Run(ctx context.Context, dir string, inv GroupInvocation, stdout io.Writer) error {
+	if inv.Verb == VerbCurrent {
+		return r.Runner.Run(ctx, dir, inv, stdout)
+	}
+
+	r.Calls = append(r.Calls, inv)
+	if r.Out == nil {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(r.Out, "  would run %s: %s\n    env: %s\n", inv.Verb, quoteArgv(inv.Argv), strings.Join(inv.Env, " "))
+	return err
+}
+
+// quoteArgv renders argv for display, quoting arguments that a shell would
+// split or interpret.
+func quoteArgv(argv []string) string {
+	quoted := make([]string, 0, len(argv))
+	for _, arg := range argv {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`|&;<>()*?[]#~") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
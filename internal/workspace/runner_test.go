@@ -3,9 +3,15 @@ package workspace
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGroupInvocations(t *testing.T) {
@@ -40,7 +46,7 @@ func TestGroupInvocations(t *testing.T) {
 		},
 		{
 			name:     "next",
-			invoke:   func() (GroupInvocation, error) { return NewNextInvocation(group, "2.0.0") },
+			invoke:   func() (GroupInvocation, error) { return NewNextInvocation(group, "2.0.0", nil) },
 			wantVerb: VerbNext,
 			wantArgv: []string{"bumper", "builtins", "next:file", "--file", "VERSION"},
 			wantEnv:  []string{"BUMPER_GROUP=api", "BUMPER_GROUP_NEXT_VERSION=2.0.0"},
@@ -96,7 +102,7 @@ func TestGroupInvocationsMissingCommand(t *testing.T) {
 		invoke func() (GroupInvocation, error)
 	}{
 		{"current", func() (GroupInvocation, error) { return NewCurrentInvocation(group) }},
-		{"next", func() (GroupInvocation, error) { return NewNextInvocation(group, "1.0.0") }},
+		{"next", func() (GroupInvocation, error) { return NewNextInvocation(group, "1.0.0", nil) }},
 		{"changelog", func() (GroupInvocation, error) { return NewChangelogInvocation(group, "1.0.0", status) }},
 		{"cat", func() (GroupInvocation, error) { return NewCatInvocation(group, "1.0.0") }},
 	}
@@ -200,6 +206,43 @@ func TestGetNextVersion(t *testing.T) {
 
 var _ Runner = ExecRunner{}
 var _ Runner = (*FakeRunner)(nil)
+var _ Runner = (*RecordingRunner)(nil)
+
+func TestRecordingRunner(t *testing.T) {
+	inner := &FakeRunner{Stdout: map[Verb]string{VerbCurrent: "1.2.3\n"}}
+	out := new(bytes.Buffer)
+	runner := &RecordingRunner{Runner: inner, Out: out}
+
+	stdout := new(bytes.Buffer)
+	current := GroupInvocation{Verb: VerbCurrent, Argv: []string{"print-version"}, Env: []string{"BUMPER_GROUP=api"}}
+	if err := runner.Run(t.Context(), "/repo", current, stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stdout.String(); got != "1.2.3\n" {
+		t.Errorf("current stdout = %q, want it passed through", got)
+	}
+
+	next := GroupInvocation{
+		Verb: VerbNext,
+		Argv: []string{"write-version", "--message", "it's done"},
+		Env:  []string{"BUMPER_GROUP=api", "BUMPER_GROUP_NEXT_VERSION=1.3.0"},
+	}
+	if err := runner.Run(t.Context(), "/repo", next, stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(inner.Calls) != 1 || inner.Calls[0].Invocation.Verb != VerbCurrent {
+		t.Errorf("inner calls = %#v, want only the current invocation", inner.Calls)
+	}
+	if !reflect.DeepEqual(runner.Calls, []GroupInvocation{next}) {
+		t.Errorf("recorded = %#v, want the next invocation", runner.Calls)
+	}
+
+	want := "  would run next: write-version --message \"it's done\"\n    env: BUMPER_GROUP=api BUMPER_GROUP_NEXT_VERSION=1.3.0\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
 
 func TestExecRunner(t *testing.T) {
 	inv := GroupInvocation{
@@ -216,3 +259,155 @@ func TestExecRunner(t *testing.T) {
 		t.Errorf("stdout = %q, want %q", got, "api")
 	}
 }
+
+func TestExecRunnerChangelogInput(t *testing.T) {
+	group := ReleaseGroup{
+		Name:         "api",
+		DisplayName:  "API",
+		ChangelogCMD: []string{"sh", "-c", `printf '%s\n' "$BUMPER_CHANGELOG_INPUT" && cat "$BUMPER_CHANGELOG_INPUT"`},
+	}
+	status := &ReleaseGroupStatus{
+		MinorLogs: []LogEntry{{
+			Timestamp:   time.Unix(100, 0).UnixNano(),
+			Commit:      "aaaaaaa",
+			SHA:         "aaaaaaa2222",
+			Content:     "aaaaaaa: feature",
+			Message:     "feature",
+			AuthorName:  "Ada",
+			AuthorEmail: "ada@example.com",
+			File:        "bump-1.md",
+		}},
+		PatchLogs: []LogEntry{{Content: "Updated dependency sdk to 1.1.0"}},
+	}
+
+	inv, err := NewChangelogInvocation(group, "1.1.0", status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stdout := new(bytes.Buffer)
+	if err := (ExecRunner{}).Run(t.Context(), t.TempDir(), inv, stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path, doc, _ := strings.Cut(stdout.String(), "\n")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("changelog input %s was not removed: %v", path, err)
+	}
+
+	var input ChangelogInput
+	if err := json.Unmarshal([]byte(doc), &input); err != nil {
+		t.Fatalf("decode changelog input: %v", err)
+	}
+
+	want := ChangelogInput{
+		Group:       "api",
+		DisplayName: "API",
+		Version:     "1.1.0",
+		Major:       []ChangelogEntry{},
+		Minor: []ChangelogEntry{{
+			Content:     "aaaaaaa: feature",
+			Message:     "feature",
+			SHA:         "aaaaaaa2222",
+			AuthorName:  "Ada",
+			AuthorEmail: "ada@example.com",
+			Date:        time.Unix(100, 0),
+			File:        "bump-1.md",
+		}},
+		Patch: []ChangelogEntry{{Content: "Updated dependency sdk to 1.1.0", Message: "Updated dependency sdk to 1.1.0"}},
+	}
+	if input.Minor[0].Date.Equal(want.Minor[0].Date) {
+		input.Minor[0].Date = want.Minor[0].Date
+	}
+	if !reflect.DeepEqual(input, want) {
+		t.Errorf("input = %+v, want %+v", input, want)
+	}
+}
+
+func TestExecRunnerBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("get working directory: %v", err)
+	}
+
+	var gotArgs []string
+	var gotEnv Environ
+	var gotGroup, gotLock, gotInput string
+	runner := ExecRunner{
+		Env: []string{LockEnv + "=token"},
+		Builtin: func(_ context.Context, env Environ, args []string, stdout io.Writer, _ io.Writer) error {
+			gotArgs = args
+			gotEnv = env
+			gotGroup, _ = env.LookupEnv("BUMPER_GROUP")
+			gotLock, _ = env.LookupEnv(LockEnv)
+			inputPath, _ := env.LookupEnv(ChangelogInputEnv)
+			if input, err := os.ReadFile(inputPath); err == nil {
+				gotInput = string(input)
+			}
+			_, err := io.WriteString(stdout, "1.2.3")
+			return err
+		},
+	}
+	inv := GroupInvocation{
+		Verb:  VerbChangelog,
+		Argv:  []string{"bumper", "builtins", "amendlog:default", "--group", "api"},
+		Env:   []string{"BUMPER_GROUP=api"},
+		Input: []byte(`{"group":"api"}`),
+	}
+
+	stdout := new(bytes.Buffer)
+	if err := runner.Run(t.Context(), dir, inv, stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"amendlog:default", "--group", "api"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("args = %q, want %q", gotArgs, want)
+	}
+	if gotEnv.Dir != dir {
+		t.Errorf("working directory = %q, want %q", gotEnv.Dir, dir)
+	}
+	if got := gotEnv.Path("CHANGELOG.md"); got != filepath.Join(dir, "CHANGELOG.md") {
+		t.Errorf("relative path resolves to %q, want it in %q", got, dir)
+	}
+	if gotGroup != "api" || gotLock != "token" {
+		t.Errorf("BUMPER_GROUP = %q, %s = %q, want api and token", gotGroup, LockEnv, gotLock)
+	}
+	if gotInput != `{"group":"api"}` {
+		t.Errorf("changelog input = %q, want the invocation's input", gotInput)
+	}
+	if got := stdout.String(); got != "1.2.3" {
+		t.Errorf("stdout = %q, want %q", got, "1.2.3")
+	}
+
+	if got, _ := os.Getwd(); got != wd {
+		t.Errorf("working directory = %q, want the process's %q untouched", got, wd)
+	}
+	if _, ok := os.LookupEnv("BUMPER_GROUP"); ok {
+		t.Error("BUMPER_GROUP is set in the process environment")
+	}
+}
+
+func TestEnvironLookupEnv(t *testing.T) {
+	t.Setenv("BUMPER_TEST_PROCESS", "process")
+	t.Setenv("BUMPER_TEST_GROUP", "process")
+
+	env := Environ{Env: []string{"BUMPER_TEST_GROUP=api", "BUMPER_TEST_GROUP=web", "BUMPER_TEST_EMPTY="}}
+
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{key: "BUMPER_TEST_GROUP", want: "web", wantOK: true},
+		{key: "BUMPER_TEST_EMPTY", want: "", wantOK: true},
+		{key: "BUMPER_TEST_PROCESS", want: "process", wantOK: true},
+		{key: "BUMPER_TEST_UNSET", want: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := env.LookupEnv(tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("LookupEnv(%q) = %q, %v, want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/format/index"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// WorktreeSnapshot is the state of a git worktree and its .bumper directory
+// at one point in time, taken before a release so a failed release can be
+// rolled back. Files that match HEAD are not copied: they are restored from
+// HEAD. Ignored files outside .bumper are not covered.
+type WorktreeSnapshot struct {
+	repo *git.Repository
+	root string
+	// bumperDir is the .bumper directory, whose files are captured even
+	// when ignored, e.g. the commit checkpoint.
+	bumperDir string
+	// files holds the files that differed from HEAD, and the files in
+	// bumperDir, keyed by absolute path.
+	files map[string]snapshotFile
+	index *index.Index
+}
+
+type snapshotFile struct {
+	// exists is false for files deleted from the worktree.
+	exists  bool
+	content []byte
+	mode    os.FileMode
+}
+
+// SnapshotWorktree records the state of the git worktree containing dir:
+// every file that differs from HEAD, including untracked files, the files in
+// the .bumper directory and the staging index.
+func SnapshotWorktree(dir string) (*WorktreeSnapshot, error) {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("read git index: %w", err)
+	}
+
+	snapshot := &WorktreeSnapshot{
+		repo:      repo,
+		root:      worktree.Filesystem().Root(),
+		bumperDir: Dir(dir),
+		files:     map[string]snapshotFile{},
+		index:     idx,
+	}
+
+	changed, err := snapshot.changedFiles()
+	if err != nil {
+		return nil, err
+	}
+	state, err := snapshot.stateFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filename := range append(changed, state...) {
+		file, err := readSnapshotFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.files[filename] = file
+	}
+
+	return snapshot, nil
+}
+
+func readSnapshotFile(filename string) (snapshotFile, error) {
+	info, err := os.Stat(filename)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return snapshotFile{}, nil
+	case err != nil:
+		return snapshotFile{}, fmt.Errorf("stat %s: %w", filename, err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return snapshotFile{}, fmt.Errorf("read %s: %w", filename, err)
+	}
+
+	return snapshotFile{exists: true, content: content, mode: info.Mode().Perm()}, nil
+}
+
+// changedFiles lists the absolute paths of the worktree files that differ
+// from HEAD.
+func (s *WorktreeSnapshot) changedFiles() ([]string, error) {
+	worktree, err := s.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree status: %w", err)
+	}
+
+	changed := make([]string, 0, len(status))
+	for path, fs := range status {
+		if isLockPath(path) {
+			continue
+		}
+		if fs.Staging != git.Unmodified || fs.Worktree != git.Unmodified {
+			changed = append(changed, filepath.Join(s.root, filepath.FromSlash(path)))
+		}
+	}
+
+	return changed, nil
+}
+
+// stateFiles lists the regular files directly in the .bumper directory,
+// except the lock file: the lock is held across the restore.
+func (s *WorktreeSnapshot) stateFiles() ([]string, error) {
+	entries, err := os.ReadDir(s.bumperDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read %s: %w", s.bumperDir, err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && entry.Name() != filepath.Base(LockFilename("")) {
+			files = append(files, filepath.Join(s.bumperDir, entry.Name()))
+		}
+	}
+
+	return files, nil
+}
+
+// Restore returns the worktree, the .bumper directory and the staging index
+// to the snapshot's state. Files changed since the snapshot are rewritten,
+// files created since are removed and deleted files are recreated.
+// Directories created since the snapshot are left in place.
+func (s *WorktreeSnapshot) Restore() error {
+	changed, err := s.changedFiles()
+	if err != nil {
+		return err
+	}
+	state, err := s.stateFiles()
+	if err != nil {
+		return err
+	}
+
+	var tree *object.Tree
+	head, err := s.repo.Head()
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		// No commits yet: every file not in the snapshot is new.
+	case err != nil:
+		return fmt.Errorf("get HEAD: %w", err)
+	default:
+		commit, err := s.repo.CommitObject(head.Hash())
+		if err != nil {
+			return fmt.Errorf("get HEAD commit: %w", err)
+		}
+		tree, err = commit.Tree()
+		if err != nil {
+			return fmt.Errorf("get HEAD tree: %w", err)
+		}
+	}
+
+	for _, filename := range append(changed, state...) {
+		if _, ok := s.files[filename]; ok {
+			continue
+		}
+		file, err := s.headFile(tree, filename)
+		if err != nil {
+			return err
+		}
+		if err := file.restore(filename); err != nil {
+			return err
+		}
+	}
+
+	for filename, file := range s.files {
+		if err := file.restore(filename); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Storer.SetIndex(s.index); err != nil {
+		return fmt.Errorf("restore git index: %w", err)
+	}
+
+	return nil
+}
+
+// headFile reads filename's content at HEAD. It reports a missing file for
+// paths HEAD does not have.
+func (s *WorktreeSnapshot) headFile(tree *object.Tree, filename string) (snapshotFile, error) {
+	if tree == nil {
+		return snapshotFile{}, nil
+	}
+
+	rel, err := filepath.Rel(s.root, filename)
+	if err != nil {
+		return snapshotFile{}, fmt.Errorf("get path relative to git root: %w", err)
+	}
+
+	f, err := tree.File(filepath.ToSlash(rel))
+	switch {
+	case errors.Is(err, object.ErrFileNotFound):
+		return snapshotFile{}, nil
+	case err != nil:
+		return snapshotFile{}, fmt.Errorf("read %s at HEAD: %w", rel, err)
+	}
+
+	content, err := f.Contents()
+	if err != nil {
+		return snapshotFile{}, fmt.Errorf("read %s at HEAD: %w", rel, err)
+	}
+	mode, err := f.Mode.ToOSFileMode()
+	if err != nil {
+		return snapshotFile{}, fmt.Errorf("read mode of %s at HEAD: %w", rel, err)
+	}
+
+	return snapshotFile{exists: true, content: []byte(content), mode: mode.Perm()}, nil
+}
+
+func (f snapshotFile) restore(filename string) error {
+	if !f.exists {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", filename, err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("create directory of %s: %w", filename, err)
+	}
+	if err := WriteFileAtomic(filename, f.content, f.mode); err != nil {
+		return fmt.Errorf("restore %s: %w", filename, err)
+	}
+	// WriteFileAtomic keeps the mode of existing files.
+	if err := os.Chmod(filename, f.mode); err != nil {
+		return fmt.Errorf("restore mode of %s: %w", filename, err)
+	}
+
+	return nil
+}
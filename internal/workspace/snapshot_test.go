@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestWorktreeSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+
+	write := func(name string, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	read := func(name string) (string, bool) {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false
+		}
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(content), true
+	}
+
+	write(".gitignore", ".bumper/commit-checkpoint.toml\n")
+	write("version.txt", "1.0.0\n")
+	write("CHANGELOG.md", "# Changelog\n")
+	write(".bumper/bump-a.md", "committed bump\n")
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	for _, name := range []string{".gitignore", "version.txt", "CHANGELOG.md", ".bumper/bump-a.md"} {
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("git add: %v", err)
+		}
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	if _, err := worktree.Commit("initial commit", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	// Uncommitted work that predates the release.
+	write("CHANGELOG.md", "# Changelog\n\nwork in progress\n")
+	write(".bumper/bump-b.md", "untracked bump\n")
+
+	snapshot, err := SnapshotWorktree(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A release that fails partway through.
+	write("version.txt", "1.1.0\n")
+	write("CHANGELOG.md", "# Changelog\n\n## a 1.1.0\n")
+	write("services/api/CHANGELOG.md", "# Changelog\n")
+	write(".bumper/commit-checkpoint.toml", "[released]\n")
+	for _, name := range []string{".bumper/bump-a.md", ".bumper/bump-b.md"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("remove %s: %v", name, err)
+		}
+	}
+	if _, err := worktree.Add("version.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"version.txt":       "1.0.0\n",
+		"CHANGELOG.md":      "# Changelog\n\nwork in progress\n",
+		".bumper/bump-a.md": "committed bump\n",
+		".bumper/bump-b.md": "untracked bump\n",
+	}
+	for name, content := range want {
+		got, ok := read(name)
+		if !ok || got != content {
+			t.Errorf("%s = %q (exists %v), want %q", name, got, ok, content)
+		}
+	}
+	for _, name := range []string{"services/api/CHANGELOG.md", ".bumper/commit-checkpoint.toml"} {
+		if _, ok := read(name); ok {
+			t.Errorf("%s exists, want it removed", name)
+		}
+	}
+
+	// Staged changes are listed too, so this also checks the index.
+	changes, err := WorktreeChanges(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{".bumper/bump-b.md", "CHANGELOG.md"}; !slices.Equal(changes, want) {
+		t.Errorf("worktree changes = %v, want %v", changes, want)
+	}
+
+}
+
+func TestSnapshotWorktreeRequiresGit(t *testing.T) {
+	if _, err := SnapshotWorktree(t.TempDir()); !errors.Is(err, errNoGitRepository) {
+		t.Errorf("error = %v, want errNoGitRepository", err)
+	}
+}
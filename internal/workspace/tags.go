@@ -0,0 +1,243 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// ErrTagExists is returned instead of moving a tag that already exists.
+var ErrTagExists = errors.New("tag already exists")
+
+// CreateTag creates an annotated tag named name on HEAD of the git
+// repository containing dir. The tagger is read from the git configuration.
+func CreateTag(dir string, name string, message string) error {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("get HEAD: %w", err)
+	}
+
+	_, err = repo.CreateTag(name, head.Hash(), &git.CreateTagOptions{Message: message})
+	switch {
+	case errors.Is(err, git.ErrTagExists):
+		return fmt.Errorf("%s: %w", name, ErrTagExists)
+	case errors.Is(err, git.ErrMissingTagger):
+		return fmt.Errorf("create tag %s: set user.name and user.email in the git configuration: %w", name, err)
+	case err != nil:
+		return fmt.Errorf("create tag %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// TagRelease tags HEAD as the release of group at version, annotated with
+// the group's release notes from its cat command. It returns the tag name.
+func TagRelease(ctx context.Context, runner Runner, dir string, group ReleaseGroup, version string) (string, error) {
+	name := group.TagName(version)
+	if name == "" {
+		return "", errors.New("no tag format defined for release group")
+	}
+
+	notes, err := GetReleaseNotes(ctx, runner, dir, group, version)
+	if err != nil {
+		return "", err
+	}
+
+	message := strings.TrimSpace(notes)
+	if message == "" {
+		message = fmt.Sprintf("%s %s", group.Name, version)
+	}
+
+	if err := CreateTag(dir, name, message); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// DefaultTagFormat is the tag format assumed for groups without a tag_format
+// when reading versions from tags.
+const DefaultTagFormat = "v" + tagVersionPlaceholder
+
+// ParseTagVersion extracts the version from a tag name created with format. It
+// reports false when the name does not match the format or the version part
+// is not a strict semantic version.
+func ParseTagVersion(format string, name string) (*semver.Version, bool) {
+	prefix, suffix, ok := strings.Cut(format, tagVersionPlaceholder)
+	if !ok || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) || len(name) < len(prefix)+len(suffix) {
+		return nil, false
+	}
+
+	v, err := semver.StrictNewVersion(name[len(prefix) : len(name)-len(suffix)])
+	if err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// TaggedVersion is a version read from a git tag and the commit it tags.
+type TaggedVersion struct {
+	Version *semver.Version
+	Commit  plumbing.Hash
+}
+
+// LatestTag returns the highest version among the tags matching format that
+// are reachable from HEAD of the git repository containing dir, or nil when
+// there is none. Shallow clones are deepened while a higher matching tag
+// points at a commit missing from the fetched history. A higher tag on a
+// fetched commit that HEAD does not reach, e.g. on another branch, is
+// ignored.
+func LatestTag(ctx context.Context, logger *slog.Logger, dir string, format string) (*TaggedVersion, error) {
+	repo, err := openGitRepository(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("get worktree: %w", err)
+	}
+	gitRoot := worktree.Filesystem().Root()
+
+	for attempt := 1; ; attempt++ {
+		candidates, err := matchingTags(repo, format)
+		if err != nil {
+			return nil, err
+		}
+
+		reachable, err := reachableCommits(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		latest, missedHigher := latestReachable(candidates, reachable, func(hash plumbing.Hash) bool {
+			_, err := repo.CommitObject(hash)
+			return errors.Is(err, plumbing.ErrObjectNotFound)
+		})
+		if !missedHigher {
+			return latest, nil
+		}
+
+		isShallow, err := isShallowRepo(repo)
+		if err != nil {
+			return nil, fmt.Errorf("check if repo is shallow: %w", err)
+		}
+		if !isShallow {
+			return latest, nil
+		}
+
+		if attempt == 10 {
+			logger.WarnContext(ctx, "could not fetch enough history to check every matching tag", slog.String("dir", dir))
+			return latest, nil
+		}
+
+		repo, err = deepenShallowRepo(ctx, gitRoot, 50)
+		if err != nil {
+			return nil, fmt.Errorf("deepen shallow repo: %w", err)
+		}
+	}
+}
+
+// latestReachable returns the highest candidate version tagged on a reachable
+// commit. missedHigher reports whether a higher candidate sits on a missing
+// commit, which in a shallow clone may be beyond the boundary.
+func latestReachable(candidates map[plumbing.Hash]*semver.Version, reachable map[plumbing.Hash]struct{}, missing func(plumbing.Hash) bool) (latest *TaggedVersion, missedHigher bool) {
+	for hash, v := range candidates {
+		if _, ok := reachable[hash]; ok && (latest == nil || v.GreaterThan(latest.Version)) {
+			latest = &TaggedVersion{Version: v, Commit: hash}
+		}
+	}
+
+	for hash, v := range candidates {
+		if _, ok := reachable[hash]; !ok && (latest == nil || v.GreaterThan(latest.Version)) && missing(hash) {
+			return latest, true
+		}
+	}
+
+	return latest, false
+}
+
+// matchingTags maps the commits tagged with names matching format to the
+// highest version tagged on each.
+func matchingTags(repo *git.Repository, format string) (map[plumbing.Hash]*semver.Version, error) {
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer refs.Close()
+
+	candidates := make(map[plumbing.Hash]*semver.Version)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		v, ok := ParseTagVersion(format, ref.Name().Short())
+		if !ok {
+			return nil
+		}
+
+		target := ref.Hash()
+		tag, err := repo.TagObject(target)
+		switch {
+		case errors.Is(err, plumbing.ErrObjectNotFound):
+			// Lightweight tag: the reference points at the commit.
+		case err != nil:
+			return fmt.Errorf("read tag %s: %w", ref.Name().Short(), err)
+		case tag.TargetType != plumbing.CommitObject:
+			// Tags of trees or blobs carry no version history.
+			return nil
+		default:
+			// The commit itself may be missing from a shallow clone.
+			target = tag.Target
+		}
+
+		if existing, ok := candidates[target]; !ok || v.GreaterThan(existing) {
+			candidates[target] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// reachableCommits returns the commits reachable from HEAD within the fetched
+// history.
+func reachableCommits(repo *git.Repository) (map[plumbing.Hash]struct{}, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("get HEAD: %w", err)
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("get commit log: %w", err)
+	}
+	defer commitIter.Close()
+
+	reachable := make(map[plumbing.Hash]struct{})
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = struct{}{}
+		return nil
+	})
+	switch {
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		// The walk reached a shallow-clone boundary.
+	case err != nil:
+		return nil, fmt.Errorf("iterate commits: %w", err)
+	}
+
+	return reachable, nil
+}
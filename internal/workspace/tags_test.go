@@ -0,0 +1,236 @@
+package workspace
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+func TestTagName(t *testing.T) {
+	group := ReleaseGroup{Name: "api", TagFormat: "api/v{{version}}"}
+	if got := group.TagName("1.2.0"); got != "api/v1.2.0" {
+		t.Errorf("tag name = %q, want %q", got, "api/v1.2.0")
+	}
+
+	if got := (ReleaseGroup{Name: "web"}).TagName("1.2.0"); got != "" {
+		t.Errorf("tag name without format = %q, want empty", got)
+	}
+}
+
+func TestValidateConfigTagFormat(t *testing.T) {
+	group := validGroup("api")
+	group.TagFormat = "api/latest"
+
+	err := validateConfig(&Config{Groups: []ReleaseGroup{group}})
+	if err == nil {
+		t.Fatal("expected an error for a tag format without a placeholder")
+	}
+}
+
+func TestTagRelease(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("read repository config: %v", err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("write repository config: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+	if _, err := worktree.Add("README.md"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	head, err := worktree.Commit("initial commit", &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+
+	group := validGroup("api")
+	group.TagFormat = "api/v{{version}}"
+	runner := &FakeRunner{Stdout: map[Verb]string{VerbCat: "## api 1.2.0\n\n- Added something\n"}}
+
+	name, err := TagRelease(t.Context(), runner, dir, group, "1.2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "api/v1.2.0" {
+		t.Errorf("tag name = %q, want %q", name, "api/v1.2.0")
+	}
+
+	ref, err := repo.Tag(name)
+	if err != nil {
+		t.Fatalf("read tag: %v", err)
+	}
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("expected an annotated tag: %v", err)
+	}
+	if tag.Target != head {
+		t.Errorf("tag target = %s, want HEAD %s", tag.Target, head)
+	}
+	if want := "## api 1.2.0\n\n- Added something\n"; tag.Message != want {
+		t.Errorf("tag message = %q, want %q", tag.Message, want)
+	}
+
+	_, err = TagRelease(t.Context(), runner, dir, group, "1.2.0")
+	if !errors.Is(err, ErrTagExists) {
+		t.Errorf("second tag err = %v, want ErrTagExists", err)
+	}
+}
+
+func TestParseTagVersion(t *testing.T) {
+	tests := []struct {
+		format string
+		name   string
+		want   string
+	}{
+		{format: "v{{version}}", name: "v1.2.3", want: "1.2.3"},
+		{format: "v{{version}}", name: "v1.2.3-rc.1", want: "1.2.3-rc.1"},
+		{format: "api/v{{version}}", name: "api/v2.0.0", want: "2.0.0"},
+		{format: "{{version}}-api", name: "2.0.0-api", want: "2.0.0"},
+		{format: "api/v{{version}}", name: "web/v2.0.0"},
+		{format: "v{{version}}", name: "v1.2"},
+		{format: "v{{version}}", name: "vnext"},
+		{format: "{{version}}", name: "v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.name, func(t *testing.T) {
+			v, ok := ParseTagVersion(tt.format, tt.name)
+			switch {
+			case tt.want == "" && ok:
+				t.Errorf("parsed %s, want no match", v)
+			case tt.want != "" && !ok:
+				t.Errorf("no match, want %s", tt.want)
+			case ok && v.String() != tt.want:
+				t.Errorf("version = %s, want %s", v, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	dir := t.TempDir()
+	repo := initTestRepo(t, dir)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("get worktree: %v", err)
+	}
+
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	commit := func(msg string) plumbing.Hash {
+		t.Helper()
+		hash, err := worktree.Commit(msg, &git.CommitOptions{Author: sig, Committer: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatalf("git commit: %v", err)
+		}
+		return hash
+	}
+	tag := func(name string, hash plumbing.Hash, annotated bool) {
+		t.Helper()
+		var opts *git.CreateTagOptions
+		if annotated {
+			opts = &git.CreateTagOptions{Tagger: sig, Message: name}
+		}
+		if _, err := repo.CreateTag(name, hash, opts); err != nil {
+			t.Fatalf("create tag %s: %v", name, err)
+		}
+	}
+
+	first := commit("first")
+	tag("api/v1.1.0", first, false)
+	tag("v3.0.0", first, false)
+	second := commit("second")
+	tag("api/v1.2.0", second, true)
+
+	// A higher version on a branch HEAD does not contain.
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("get HEAD: %v", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("other"), Create: true}); err != nil {
+		t.Fatalf("checkout other: %v", err)
+	}
+	tag("api/v9.0.0", commit("unreleased"), true)
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: head.Name()}); err != nil {
+		t.Fatalf("checkout %s: %v", head.Name(), err)
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+
+	latest, err := LatestTag(t.Context(), logger, dir, "api/v{{version}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest == nil || latest.Version.String() != "1.2.0" || latest.Commit != second {
+		t.Errorf("latest = %+v, want 1.2.0 at %s", latest, second)
+	}
+
+	latest, err = LatestTag(t.Context(), logger, dir, "web/v{{version}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest != nil {
+		t.Errorf("latest = %+v, want none for an unmatched format", latest)
+	}
+}
+
+// Only a higher tag on a commit missing from the object store calls for
+// deepening a shallow clone; one on a fetched commit HEAD does not reach
+// never will be reachable.
+func TestLatestReachable(t *testing.T) {
+	released := plumbing.NewHash("1111111111111111111111111111111111111111")
+	branch := plumbing.NewHash("2222222222222222222222222222222222222222")
+	beyond := plumbing.NewHash("3333333333333333333333333333333333333333")
+	reachable := map[plumbing.Hash]struct{}{released: {}}
+	missing := func(hash plumbing.Hash) bool { return hash == beyond }
+
+	tests := []struct {
+		name       string
+		candidates map[plumbing.Hash]*semver.Version
+		wantMissed bool
+	}{
+		{
+			name:       "higher tag on another branch",
+			candidates: map[plumbing.Hash]*semver.Version{released: semver.MustParse("1.0.0"), branch: semver.MustParse("2.0.0")},
+		},
+		{
+			name:       "higher tag beyond the shallow boundary",
+			candidates: map[plumbing.Hash]*semver.Version{released: semver.MustParse("1.0.0"), beyond: semver.MustParse("2.0.0")},
+			wantMissed: true,
+		},
+		{
+			name:       "lower tag beyond the shallow boundary",
+			candidates: map[plumbing.Hash]*semver.Version{released: semver.MustParse("1.0.0"), beyond: semver.MustParse("0.9.0")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest, missed := latestReachable(tt.candidates, reachable, missing)
+			if latest == nil || latest.Commit != released || missed != tt.wantMissed {
+				t.Errorf("latestReachable() = %+v, %v, want 1.0.0 at %s, %v", latest, missed, released, tt.wantMissed)
+			}
+		})
+	}
+}